@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -9,6 +10,8 @@ import (
 
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/adapter/handler/http"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/adapter/keystore"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/adapter/notifier"
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/adapter/storage/postgres"
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/adapter/storage/postgres/repository"
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/adapter/storage/redis"
@@ -45,6 +48,18 @@ func main() {
 	// 	&domain.User{},
 	// 	&domain.Role{},
 	// 	&domain.Permission{},
+	// 	&domain.RefreshToken{},
+	// 	&domain.ResourceRoleBinding{},
+	// 	&domain.Tenant{},
+	// 	&domain.TenantRoleAssignment{},
+	// 	&domain.RolePermission{},
+	// 	&domain.RoleDenyRule{},
+	// 	&domain.UserDenyRule{},
+	// 	&domain.MFACredential{},
+	// 	&domain.RecoveryCode{},
+	// 	&domain.APIKey{},
+	// 	&domain.UserToken{},
+	// 	&domain.AuditEvent{},
 	// )
 	// SeedData(db)
 
@@ -52,33 +67,114 @@ func main() {
 	userRepo := repository.NewUserRepository(db)
 	roleRepo := repository.NewRoleRepository(db)
 	permissionRepo := repository.NewPermissionRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	bindingRepo := repository.NewResourceBindingRepository(db)
+	tenantRepo := repository.NewTenantRepository(db)
+	denyRepo := repository.NewDenyRuleRepository(db)
+	tokenDenylist := redis.NewTokenDenylist(rdb)
+	loginAttempts := redis.NewLoginAttemptStore(rdb)
+	mfaRepo := repository.NewMFARepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	txManager := repository.NewTransactor(db)
 
 	// --- Service Init ---
+	// Audit Log (Hash Chain) ส่งให้ทุก Service ที่แก้ไขข้อมูลสิทธิ์ / บัญชี
+	auditService := service.NewAuditService(auditRepo, cfg.Audit)
+
 	// RBAC Service (ใช้ Redis และ Repo ครบชุด)
-	rbacService := service.NewRBACService(userRepo, roleRepo, permissionRepo, rdb)
+	rbacService := service.NewRBACService(userRepo, roleRepo, permissionRepo, bindingRepo, tenantRepo, denyRepo, txManager, auditService, rdb)
 	if err := rbacService.LoadPolicy(); err != nil {
 		log.Printf("⚠️ Warning: Failed to load RBAC policy: %v", err)
 	}
 
-	// Auth Service
-	authService := service.NewAuthService(userRepo, cfg.Server.JWTSecret)
+	// Background Jobs (หยุดตอน Shutdown)
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	service.StartAssignmentSweeper(bgCtx, rbacService, cfg.RBAC.AssignmentSweepInterval)
+	// Instance อื่นแก้ Policy: รับแจ้งผ่าน Redis Pub/Sub + เทียบ Version เป็นระยะ
+	service.StartPolicySync(bgCtx, rbacService, rdb, cfg.RBAC.PolicySyncInterval)
+
+	// Auth Service (เซ็น JWT ด้วยกุญแจ Asymmetric)
+	keySet, err := keystore.NewKeySet(cfg.Auth)
+	if err != nil {
+		log.Fatalf("Failed to load signing keys: %v", err)
+	}
+	passwordPolicy, err := service.NewPasswordPolicy(cfg.Auth.PasswordPolicy)
+	if err != nil {
+		log.Fatalf("Failed to load password policy: %v", err)
+	}
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, tenantRepo, mfaRepo, rbacService, tokenDenylist, loginAttempts, passwordPolicy, keySet, auditService, cfg.Auth)
+	userService := service.NewUserService(userRepo, apiKeyRepo, authService, tokenDenylist, txManager, auditService)
+	// Service Account (Cron / Backend Integration ยืนยันตัวตนด้วย X-API-Key)
+	serviceAccountService := service.NewServiceAccountService(userRepo, apiKeyRepo, tokenDenylist, txManager, auditService)
+	// ลืมรหัสผ่าน / ยืนยันอีเมล (ส่งผ่าน Notifier: log ตอน Dev, smtp ตอน Production)
+	mailNotifier, err := notifier.NewNotifier(cfg.Notifier)
+	if err != nil {
+		log.Fatalf("Failed to init notifier: %v", err)
+	}
+	accountTokenService := service.NewAccountTokenService(userRepo, userTokenRepo, authService, loginAttempts, passwordPolicy, mailNotifier, txManager, auditService, cfg.Auth.AccountTokens)
+	tokenVerifier, err := service.NewTokenVerifier(keySet, cfg.Auth)
+	if err != nil {
+		log.Fatalf("Failed to init token verifier: %v", err)
+	}
+
+	// Policy Decision API สำหรับ Service อื่น (ยืนยันตัวตนแยกจาก User)
+	authzService := service.NewAuthzService(rbacService, tokenVerifier, authService, cfg.Authz)
+	clientAuth, err := service.NewClientAuthenticator(cfg.Authz)
+	if err != nil {
+		log.Fatalf("Failed to load authz clients: %v", err)
+	}
 
 	// --- Handler Init ---
 	authHandler := http.NewAuthHandler(authService)
 	rbacHandler := http.NewRBACHandler(rbacService) // ✅ เพิ่มตรงนี้
+	jwksHandler := http.NewJWKSHandler(keySet)
+	authzHandler := http.NewAuthzHandler(authzService)
+	userHandler := http.NewUserHandler(userService)
+	serviceAccountHandler := http.NewServiceAccountHandler(serviceAccountService)
+	accountHandler := http.NewAccountHandler(accountTokenService)
+	auditHandler := http.NewAuditHandler(auditService)
 
 	// --- Middleware Setup ---
-	// สร้างฟังก์ชันเช็คสิทธิ์ (Guard)
-	guard := http.NewRBACMiddleware(cfg, rbacService)
+	// สร้างฟังก์ชันเช็คสิทธิ์ (Guard) รับได้ทั้ง Bearer JWT และ X-API-Key
+	guard := http.NewRBACMiddleware(tokenVerifier, serviceAccountService, rbacService, authService)
+	// เช็คสิทธิ์บน Resource ที่อยู่ใน URL (เช่น /projects/:id)
+	guardOn := http.NewResourceRBACMiddleware(tokenVerifier, serviceAccountService, rbacService, authService)
+	// ตรวจแค่ Login (ไม่เช็ค Permission)
+	authenticated := http.NewAuthMiddleware(tokenVerifier, serviceAccountService, authService)
 
 	// 5. Server Setup
-	app := fiber.New()
+	// c.IP() เชื่อ ProxyHeader เฉพาะ Request ที่มาจาก TrustedProxies (Counter ต่อ IP ของ Login / อีเมล ใช้ค่านี้)
+	app := fiber.New(fiber.Config{
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: len(cfg.Server.TrustedProxies) > 0,
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		EnableIPValidation:      true,
+	})
+	// Request ID / IP สำหรับ Audit Log (ต้องอยู่ก่อนทุก Route)
+	app.Use(http.NewRequestMetaMiddleware())
+	app.Get("/.well-known/jwks.json", jwksHandler.GetJWKS)
 	api := app.Group("/api")
 
 	// --- Public Routes ---
 	auth := api.Group("/auth")
 	auth.Post("/register", authHandler.Register)
 	auth.Post("/login", authHandler.Login)
+	auth.Post("/refresh", authHandler.Refresh)
+	auth.Post("/logout", authenticated, authHandler.Logout)
+	auth.Post("/change-password", authHandler.ChangePassword)
+	auth.Post("/forgot-password", accountHandler.ForgotPassword)
+	auth.Post("/reset-password", accountHandler.ResetPassword)
+	auth.Post("/verify-email", accountHandler.VerifyEmail)
+	auth.Post("/mfa/verify", authHandler.VerifyMFA)
+	auth.Post("/mfa/enroll", authHandler.BeginChallengeEnrollment)
+	auth.Post("/mfa/enroll/confirm", authHandler.ConfirmChallengeEnrollment)
+
+	// --- Service-to-Service Routes ---
+	authz := api.Group("/authz", http.NewServiceClientMiddleware(clientAuth))
+	authz.Post("/check", authzHandler.Check)
+	authz.Post("/check-batch", authzHandler.CheckBatch)
 
 	// --- Protected Routes ---
 	api.Get("/admin/dashboard", guard("dashboard:view"), func(c *fiber.Ctx) error {
@@ -87,6 +183,15 @@ func main() {
 	api.Get("/profile", guard("profile:view"), func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{"message": "Hello User! This is your profile."})
 	})
+	api.Get("/me/permissions", authenticated, rbacHandler.MyPermissions)
+	api.Post("/me/email/verification", authenticated, accountHandler.RequestEmailVerification)
+	api.Post("/me/mfa/enroll", authenticated, authHandler.BeginMyMFAEnrollment)
+	api.Post("/me/mfa/confirm", authenticated, authHandler.ConfirmMyMFAEnrollment)
+	api.Post("/me/mfa/disable", authenticated, authHandler.DisableMyMFA)
+	api.Post("/me/mfa/recovery-codes", authenticated, authHandler.RegenerateMyRecoveryCodes)
+	api.Get("/projects/:id", guardOn("project:view", "project", "id"), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"message": "Hello! This is project " + c.Params("id")})
+	})
 
 	// --- RBAC Management Routes ---
 	adminPanel := api.Group("/admin/panel", guard("system:admin"))
@@ -94,6 +199,7 @@ func main() {
 	// GET Routes สำหรับดูข้อมูล (เพิ่มเข้ามาใหม่)
 	adminPanel.Get("/roles", rbacHandler.GetRoles)
 	adminPanel.Get("/permissions", rbacHandler.GetPermissions)
+	adminPanel.Get("/permissions/:name/holders", rbacHandler.GetPermissionHolders)
 	adminPanel.Get("/users/:id/roles", rbacHandler.GetUserRoles) // สังเกตการใช้ :id
 
 	// POST / DELETE Routes (ของเดิม)
@@ -103,6 +209,56 @@ func main() {
 	adminPanel.Post("/users/assign-role", rbacHandler.AssignRole)
 	adminPanel.Delete("/roles/remove-perm", rbacHandler.RemovePermission)
 	adminPanel.Delete("/users/remove-role", rbacHandler.RemoveRole)
+	adminPanel.Post("/roles/assign-parent", rbacHandler.AssignParent)
+	adminPanel.Delete("/roles/remove-parent", rbacHandler.RemoveParent)
+	adminPanel.Post("/users/:id/revoke-sessions", authHandler.RevokeUserSessions)
+	adminPanel.Post("/users/:id/unlock", authHandler.UnlockUser)
+	adminPanel.Get("/users/:id/bindings", rbacHandler.GetUserBindings)
+	adminPanel.Get("/users/:id/explain", rbacHandler.Explain)
+	adminPanel.Get("/users/:id/permissions", rbacHandler.GetUserPermissions)
+	adminPanel.Post("/bindings", rbacHandler.BindResourceRole)
+	adminPanel.Delete("/bindings", rbacHandler.UnbindResourceRole)
+	adminPanel.Get("/users/:id/denies", rbacHandler.GetUserDenies)
+	adminPanel.Post("/roles/deny", rbacHandler.AddRoleDeny)
+	adminPanel.Delete("/roles/deny", rbacHandler.RemoveRoleDeny)
+	adminPanel.Post("/users/deny", rbacHandler.AddUserDeny)
+	adminPanel.Delete("/users/deny", rbacHandler.RemoveUserDeny)
+	adminPanel.Get("/tenants", rbacHandler.GetTenants)
+	adminPanel.Post("/tenants", rbacHandler.CreateTenant)
+	adminPanel.Get("/users/:id/tenant-roles", rbacHandler.GetUserTenantRoles)
+	adminPanel.Post("/tenants/assign-role", rbacHandler.AssignTenantRole)
+	adminPanel.Delete("/tenants/remove-role", rbacHandler.RemoveTenantRole)
+	adminPanel.Get("/audit", auditHandler.ListEvents)
+	adminPanel.Get("/audit/verify", auditHandler.VerifyChain)
+
+	// Role / Permission CRUD (ลงท้ายสุด เพื่อไม่ให้ /:id ทับ Route ที่เป็นชื่อตายตัวด้านบน)
+	adminPanel.Get("/roles/deleted", rbacHandler.GetDeletedRoles)
+	adminPanel.Patch("/roles/:id", rbacHandler.UpdateRole)
+	adminPanel.Delete("/roles/:id", rbacHandler.DeleteRole)
+	adminPanel.Post("/roles/:id/restore", rbacHandler.RestoreRole)
+	adminPanel.Get("/permissions/deleted", rbacHandler.GetDeletedPermissions)
+	adminPanel.Patch("/permissions/:id", rbacHandler.UpdatePermission)
+	adminPanel.Delete("/permissions/:id", rbacHandler.DeletePermission)
+	adminPanel.Post("/permissions/:id/restore", rbacHandler.RestorePermission)
+
+	// User Management
+	adminPanel.Get("/users", userHandler.ListUsers)
+	adminPanel.Get("/users/:id", userHandler.GetUser)
+	adminPanel.Patch("/users/:id", userHandler.UpdateUser)
+	adminPanel.Delete("/users/:id", userHandler.DeleteUser)
+	adminPanel.Post("/users/:id/disable", userHandler.DisableUser)
+	adminPanel.Post("/users/:id/enable", userHandler.EnableUser)
+	adminPanel.Post("/users/:id/restore", userHandler.RestoreUser)
+	adminPanel.Post("/users/:id/force-password-reset", userHandler.ForcePasswordReset)
+
+	// Service Account / API Key (Role ผูกผ่าน /users/assign-role ด้วย id ของ Service Account)
+	adminPanel.Get("/service-accounts", serviceAccountHandler.ListServiceAccounts)
+	adminPanel.Post("/service-accounts", serviceAccountHandler.CreateServiceAccount)
+	adminPanel.Delete("/service-accounts/:id", serviceAccountHandler.DeleteServiceAccount)
+	adminPanel.Get("/service-accounts/:id/keys", serviceAccountHandler.ListAPIKeys)
+	adminPanel.Post("/service-accounts/:id/keys", serviceAccountHandler.CreateAPIKey)
+	adminPanel.Post("/service-accounts/:id/keys/:keyId/rotate", serviceAccountHandler.RotateAPIKey)
+	adminPanel.Delete("/service-accounts/:id/keys/:keyId", serviceAccountHandler.RevokeAPIKey)
 
 	// ==========================================
 	// 🛑 Graceful Shutdown Setup
@@ -116,6 +272,7 @@ func main() {
 	go func() {
 		<-c // รอจนกว่าจะมีสัญญาณเข้ามา
 		fmt.Println("\n🛑 Gracefully shutting down server...")
+		stopBackground()
 
 		// ปิด Fiber App อย่างนุ่มนวล (รอให้ Request ที่ค้างอยู่ ทำงานเสร็จก่อน)
 		if err := app.Shutdown(); err != nil {
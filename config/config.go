@@ -1,7 +1,9 @@
 package config
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -10,11 +12,20 @@ type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	Redis    RedisConfig
+	Auth     AuthConfig
+	RBAC     RBACConfig
+	Authz    AuthzConfig
+	Notifier NotifierConfig
+	Audit    AuditConfig
 }
 
 type ServerConfig struct {
-	Port      string
-	JWTSecret string
+	Port string
+
+	// ProxyHeader Header ที่ Load Balancer ใส่ IP ของ Client มาให้ (เช่น X-Real-IP) ว่าง = ใช้ IP ของ Connection
+	ProxyHeader string `mapstructure:"proxy_header"`
+	// TrustedProxies IP / CIDR ของ Proxy ที่เชื่อ ProxyHeader ได้ (Request จากที่อื่นใช้ IP ของ Connection เสมอ)
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type DatabaseConfig struct {
@@ -33,12 +44,161 @@ type RedisConfig struct {
 	Password string
 }
 
+type AuthConfig struct {
+	AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl"`  // อายุ Access Token (ควรสั้น)
+	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"` // อายุ Refresh Token
+
+	// Claims ที่ Token ต้องมีและต้องตรง (iss / aud)
+	Issuer   string `mapstructure:"issuer"`
+	Audience string `mapstructure:"audience"`
+	// Algorithm ที่ยอมรับตอนตรวจ Token (ห้ามมี HS* หรือ none)
+	AllowedAlgorithms []string `mapstructure:"allowed_algorithms"`
+
+	// กุญแจสำหรับเซ็น JWT (เซ็นด้วย ActiveKeyID ตัวเดียว แต่ตรวจได้ทุกตัวในรายการ)
+	ActiveKeyID string             `mapstructure:"active_key_id"`
+	SigningKeys []SigningKeyConfig `mapstructure:"signing_keys"`
+
+	Lockout        LockoutConfig        `mapstructure:"lockout"`
+	PasswordPolicy PasswordPolicyConfig `mapstructure:"password_policy"`
+	MFA            MFAConfig            `mapstructure:"mfa"`
+	AccountTokens  AccountTokensConfig  `mapstructure:"account_tokens"`
+}
+
+// AccountTokensConfig Token ลืมรหัสผ่าน / ยืนยันอีเมล ที่ส่งไปทาง Notifier
+type AccountTokensConfig struct {
+	PasswordResetTTL     time.Duration `mapstructure:"password_reset_ttl"`
+	EmailVerificationTTL time.Duration `mapstructure:"email_verification_ttl"`
+	// LinkBaseURL หน้าเว็บที่รับ Token (ต่อท้ายด้วย /reset-password?token=... หรือ /verify-email?token=...)
+	LinkBaseURL string `mapstructure:"link_base_url"`
+
+	// ขอส่งได้ไม่เกิน MaxRequests ครั้งต่อ RequestWindow (นับแยกต่ออีเมล / IP / User)
+	MaxRequests   int64         `mapstructure:"max_requests"`
+	RequestWindow time.Duration `mapstructure:"request_window"`
+}
+
+type MFAConfig struct {
+	Issuer        string        `mapstructure:"issuer"`         // ชื่อที่แสดงใน Authenticator App
+	ChallengeTTL  time.Duration `mapstructure:"challenge_ttl"`  // อายุ Challenge Token ระหว่าง Login ขั้นที่ 1 กับ 2
+	RecoveryCodes int           `mapstructure:"recovery_codes"` // จำนวน Recovery Code ต่อชุด
+	// ChallengeMaxAttempts กรอก Code ผิดครบเท่านี้ Challenge Token นั้นใช้ไม่ได้อีก (ต้อง Login ใหม่)
+	ChallengeMaxAttempts int64 `mapstructure:"challenge_max_attempts"`
+
+	// User ที่ถือ Permission / Role เหล่านี้ต้องเปิด MFA (ถือผ่าน Parent / Wildcard ก็นับ)
+	RequiredPermissions []string `mapstructure:"required_permissions"`
+	RequiredRoles       []string `mapstructure:"required_roles"`
+}
+
+type PasswordPolicyConfig struct {
+	MinLength int `mapstructure:"min_length"` // นับเป็นตัวอักษร
+	MaxLength int `mapstructure:"max_length"` // นับเป็น Byte (bcrypt ใช้แค่ 72 Byte แรก จึงห้ามเกิน 72)
+
+	RequireUpper  bool `mapstructure:"require_upper"`
+	RequireLower  bool `mapstructure:"require_lower"`
+	RequireDigit  bool `mapstructure:"require_digit"`
+	RequireSymbol bool `mapstructure:"require_symbol"`
+
+	// ไฟล์รายการรหัสผ่านที่ห้ามใช้ (บรรทัดละรหัส ไม่สนตัวพิมพ์เล็ก/ใหญ่, # = Comment)
+	BannedPasswordsFile string `mapstructure:"banned_passwords_file"`
+}
+
+// LockoutConfig กัน Brute Force ตอน Login (นับแยกต่อ Username และต่อ IP)
+type LockoutConfig struct {
+	MaxAttempts      int64         `mapstructure:"max_attempts"`        // ผิดครบเท่านี้ต่อ Username = ล็อก
+	MaxAttemptsPerIP int64         `mapstructure:"max_attempts_per_ip"` // ผิดครบเท่านี้ต่อ IP = ล็อก IP
+	Window           time.Duration `mapstructure:"window"`              // Counter นับจากครั้งแรกที่ผิด
+	Duration         time.Duration `mapstructure:"duration"`            // ระยะเวลาที่ล็อก
+	BaseDelay        time.Duration `mapstructure:"base_delay"`          // หน่วงเวลาเพิ่มเท่าตัวทุกครั้งที่ผิด
+	MaxDelay         time.Duration `mapstructure:"max_delay"`
+}
+
+type RBACConfig struct {
+	// ความถี่ในการลบ Role Assignment ที่หมดอายุ
+	AssignmentSweepInterval time.Duration `mapstructure:"assignment_sweep_interval"`
+	// ความถี่ในการเทียบ Policy Version กับ Instance อื่น (เผื่อพลาดข้อความ Pub/Sub)
+	PolicySyncInterval time.Duration `mapstructure:"policy_sync_interval"`
+}
+
+type AuditConfig struct {
+	// DeniedSampleRate สัดส่วนการปฏิเสธสิทธิ์ (CheckAccess = false) ที่เก็บลง Audit Log (0 = ไม่เก็บ, 1 = เก็บทุกครั้ง)
+	DeniedSampleRate float64 `mapstructure:"denied_sample_rate"`
+}
+
+type AuthzConfig struct {
+	// Service ที่เรียก /api/authz ได้ (HTTP Basic: client_id / secret)
+	Clients []AuthzClientConfig `mapstructure:"clients"`
+	// จำนวนรายการสูงสุดต่อ check-batch
+	MaxBatchSize int `mapstructure:"max_batch_size"`
+}
+
+type AuthzClientConfig struct {
+	ID string `mapstructure:"id"`
+	// SHA-256 (hex) ของ Secret ห้ามเก็บ Secret ตรงๆ: echo -n "<secret>" | sha256sum
+	SecretSHA256 string `mapstructure:"secret_sha256"`
+}
+
+// NotifierConfig ช่องทางส่งอีเมล: "log" (Dev: เขียนลง Log / ไฟล์) หรือ "smtp"
+type NotifierConfig struct {
+	Driver  string     `mapstructure:"driver"`
+	From    string     `mapstructure:"from"`
+	LogFile string     `mapstructure:"log_file"` // ว่าง = เขียนลง Log ของโปรแกรม
+	SMTP    SMTPConfig `mapstructure:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	Username string `mapstructure:"username"` // ว่าง = ไม่ AUTH
+	Password string `mapstructure:"password"`
+}
+
+type SigningKeyConfig struct {
+	KID            string `mapstructure:"kid"`
+	Algorithm      string `mapstructure:"alg"`              // RS256 | EdDSA
+	PrivateKeyFile string `mapstructure:"private_key_file"` // PEM (PKCS#1 / PKCS#8)
+	PublicKeyFile  string `mapstructure:"public_key_file"`  // ใช้แทน Private Key สำหรับกุญแจที่เลิกเซ็นแล้ว (ตรวจอย่างเดียว)
+}
+
 func LoadConfig() (*Config, error) {
 	// บอก Viper ว่าไฟล์ชื่ออะไร อยู่ที่ไหน
 	viper.SetConfigName("/config/config") // ชื่อไฟล์ config.yaml
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(".") // ให้หาที่ root folder
 
+	// ค่า Default กรณีไม่ได้กำหนดใน config.yaml
+	viper.SetDefault("auth.access_token_ttl", "15m")
+	viper.SetDefault("auth.refresh_token_ttl", "720h")
+	viper.SetDefault("auth.issuer", "rbac-hexagonal")
+	viper.SetDefault("auth.audience", "rbac-api")
+	viper.SetDefault("auth.allowed_algorithms", []string{"EdDSA", "RS256"})
+	viper.SetDefault("auth.lockout.max_attempts", 5)
+	viper.SetDefault("auth.lockout.max_attempts_per_ip", 50)
+	viper.SetDefault("auth.lockout.window", "15m")
+	viper.SetDefault("auth.lockout.duration", "15m")
+	viper.SetDefault("auth.lockout.base_delay", "250ms")
+	viper.SetDefault("auth.lockout.max_delay", "4s")
+	viper.SetDefault("auth.password_policy.min_length", 10)
+	viper.SetDefault("auth.password_policy.max_length", 72)
+	viper.SetDefault("auth.password_policy.require_upper", true)
+	viper.SetDefault("auth.password_policy.require_lower", true)
+	viper.SetDefault("auth.password_policy.require_digit", true)
+	viper.SetDefault("auth.mfa.issuer", "RBAC Hexagonal")
+	viper.SetDefault("auth.mfa.challenge_ttl", "5m")
+	viper.SetDefault("auth.mfa.recovery_codes", 10)
+	viper.SetDefault("auth.mfa.challenge_max_attempts", 3)
+	viper.SetDefault("auth.mfa.required_permissions", []string{"system:admin"})
+	viper.SetDefault("auth.account_tokens.password_reset_ttl", "30m")
+	viper.SetDefault("auth.account_tokens.email_verification_ttl", "48h")
+	viper.SetDefault("auth.account_tokens.link_base_url", "http://localhost:3000")
+	viper.SetDefault("auth.account_tokens.max_requests", 3)
+	viper.SetDefault("auth.account_tokens.request_window", "1h")
+	viper.SetDefault("notifier.driver", "log")
+	viper.SetDefault("notifier.from", "no-reply@localhost")
+	viper.SetDefault("notifier.smtp.port", "587")
+	viper.SetDefault("rbac.assignment_sweep_interval", "1m")
+	viper.SetDefault("rbac.policy_sync_interval", "30s")
+	viper.SetDefault("authz.max_batch_size", 100)
+	viper.SetDefault("audit.denied_sample_rate", 0.0)
+
 	// เผื่ออยาก override ด้วย Environment Variable (เช่น SERVER_PORT=8080)
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
@@ -54,5 +214,16 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	if config.Server.ProxyHeader != "" && len(config.Server.TrustedProxies) == 0 {
+		return nil, fmt.Errorf("server.trusted_proxies is required when server.proxy_header is set")
+	}
+	// ค่าที่ใช้สร้าง time.Ticker ต้องมากกว่า 0 (ไม่งั้น Panic ตอน Start)
+	if config.RBAC.AssignmentSweepInterval <= 0 {
+		return nil, fmt.Errorf("rbac.assignment_sweep_interval must be positive, got %s", config.RBAC.AssignmentSweepInterval)
+	}
+	if config.RBAC.PolicySyncInterval <= 0 {
+		return nil, fmt.Errorf("rbac.policy_sync_interval must be positive, got %s", config.RBAC.PolicySyncInterval)
+	}
+
 	return &config, nil
 }
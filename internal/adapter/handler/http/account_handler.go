@@ -0,0 +1,86 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AccountHandler ลืมรหัสผ่าน / ยืนยันอีเมล
+type AccountHandler struct {
+	svc port.AccountTokenService
+}
+
+func NewAccountHandler(svc port.AccountTokenService) *AccountHandler {
+	return &AccountHandler{svc: svc}
+}
+
+// ForgotPassword ตอบ 202 เหมือนกันทุกกรณี ไม่บอกว่าอีเมลมีบัญชีหรือไม่
+func (h *AccountHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req port.PasswordResetRequestReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+
+	req.IP = c.IP()
+
+	if err := h.svc.RequestPasswordReset(c.UserContext(), &req); err != nil {
+		return respondAccountError(c, err)
+	}
+	return c.Status(202).JSON(fiber.Map{"message": "if the email is registered, a reset link has been sent"})
+}
+
+func (h *AccountHandler) ResetPassword(c *fiber.Ctx) error {
+	var req port.PasswordResetReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+
+	if err := h.svc.ResetPassword(c.UserContext(), &req); err != nil {
+		return respondAccountError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "password has been reset, please log in again"})
+}
+
+// RequestEmailVerification ส่งลิงก์ยืนยันไปที่อีเมลของ User ที่ Login อยู่
+func (h *AccountHandler) RequestEmailVerification(c *fiber.Ctx) error {
+	userID, _ := c.Locals(LocalUserID).(string)
+
+	if err := h.svc.RequestEmailVerification(c.UserContext(), userID); err != nil {
+		return respondAccountError(c, err)
+	}
+	return c.Status(202).JSON(fiber.Map{"message": "verification email sent"})
+}
+
+func (h *AccountHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req port.VerifyEmailReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+
+	if err := h.svc.VerifyEmail(c.UserContext(), &req); err != nil {
+		return respondAccountError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "email verified"})
+}
+
+// respondAccountError: Token ใช้ไม่ได้ = 400, ถูกระงับ = 403, ยืนยันแล้ว = 409, ข้อมูลผิด = 422, ขอบ่อยเกิน = 429
+func respondAccountError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidUserToken):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrUserDisabled):
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrEmailAlreadyVerified):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrValidation):
+		return respondValidationError(c, err)
+	case errors.Is(err, domain.ErrTooManyTokenRequests):
+		return c.Status(429).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"errors"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditHandler struct {
+	svc port.AuditService
+}
+
+func NewAuditHandler(svc port.AuditService) *AuditHandler {
+	return &AuditHandler{svc: svc}
+}
+
+// ListEvents: GET /audit?actor_id=&action=role.*&target_type=&target_id=&outcome=&request_id=&from=&to=&page=&page_size=
+// from / to เป็น RFC3339
+func (h *AuditHandler) ListEvents(c *fiber.Ctx) error {
+	query := port.AuditQuery{
+		ActorID:    c.Query("actor_id"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		Outcome:    c.Query("outcome"),
+		RequestID:  c.Query("request_id"),
+		Page:       c.QueryInt("page", 1),
+		PageSize:   c.QueryInt("page_size", 50),
+	}
+	var err error
+	if query.From, err = queryTime(c, "from"); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if query.To, err = queryTime(c, "to"); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	events, total, err := h.svc.Query(c.UserContext(), &query)
+	if err != nil {
+		return respondAuditError(c, err)
+	}
+
+	page := port.AuditPage{
+		Events:   make([]port.AuditEventResponse, 0, len(events)),
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		Total:    total,
+	}
+	for i := range events {
+		page.Events = append(page.Events, toAuditEventResponse(&events[i]))
+	}
+	return c.JSON(page)
+}
+
+// VerifyChain: GET /audit/verify ไล่ตรวจ Hash ทั้ง Chain (ตอบ 200 เสมอ ดูผลที่ valid)
+func (h *AuditHandler) VerifyChain(c *fiber.Ctx) error {
+	result, err := h.svc.Verify(c.UserContext())
+	if err != nil {
+		return respondAuditError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func queryTime(c *fiber.Ctx, key string) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, errors.New(key + " must be RFC3339")
+	}
+	return &t, nil
+}
+
+func respondAuditError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, domain.ErrInvalidQuery) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
@@ -1,6 +1,12 @@
 package http
 
 import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,7 +25,13 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
 	}
 
-	if err := h.svc.Register(c.Context(), &req); err != nil {
+	if err := h.svc.Register(c.UserContext(), &req); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrValidation):
+			return respondValidationError(c, err)
+		case errors.Is(err, domain.ErrAlreadyExists):
+			return c.Status(409).JSON(fiber.Map{"error": "username or email already in use"})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -32,10 +44,241 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
 	}
 
-	res, err := h.svc.Login(c.Context(), &req)
+	req.IP = c.IP()
+
+	res, err := h.svc.Login(c.UserContext(), &req)
 	if err != nil {
+		if errors.Is(err, domain.ErrTooManyLoginAttempts) {
+			return respondLoginLocked(c, err)
+		}
+		if errors.Is(err, domain.ErrNotTenantMember) || errors.Is(err, domain.ErrUserDisabled) {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, domain.ErrPasswordChangeRequired) {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error(), "code": "password_change_required"})
+		}
 		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.JSON(res)
 }
+
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req port.RefreshReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+
+	res, err := h.svc.Refresh(c.UserContext(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) || errors.Is(err, domain.ErrRefreshTokenReused) {
+			return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, domain.ErrUserDisabled) || errors.Is(err, domain.ErrPasswordChangeRequired) || errors.Is(err, domain.ErrMFARequired) {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(res)
+}
+
+// --- MFA: ขั้นที่ 2 ของ Login (ใช้ Challenge Token ไม่ต้องมี Access Token) ---
+
+func (h *AuthHandler) VerifyMFA(c *fiber.Ctx) error {
+	var req port.MFAVerifyReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	req.IP = c.IP()
+
+	res, err := h.svc.VerifyMFA(c.UserContext(), &req)
+	if err != nil {
+		return respondMFAError(c, err)
+	}
+	return c.JSON(res)
+}
+
+func (h *AuthHandler) BeginChallengeEnrollment(c *fiber.Ctx) error {
+	var req port.MFAVerifyReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+
+	res, err := h.svc.BeginChallengeEnrollment(c.UserContext(), req.ChallengeToken)
+	if err != nil {
+		return respondMFAError(c, err)
+	}
+	return c.JSON(res)
+}
+
+func (h *AuthHandler) ConfirmChallengeEnrollment(c *fiber.Ctx) error {
+	var req port.MFAVerifyReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	req.IP = c.IP()
+
+	res, err := h.svc.ConfirmChallengeEnrollment(c.UserContext(), &req)
+	if err != nil {
+		return respondMFAError(c, err)
+	}
+	return c.JSON(res)
+}
+
+// --- MFA: จัดการของตัวเอง (ต้องผ่าน NewAuthMiddleware) ---
+
+func (h *AuthHandler) BeginMyMFAEnrollment(c *fiber.Ctx) error {
+	userID, _ := c.Locals(LocalUserID).(string)
+	res, err := h.svc.BeginMFAEnrollment(c.UserContext(), userID)
+	if err != nil {
+		return respondMFAError(c, err)
+	}
+	return c.JSON(res)
+}
+
+func (h *AuthHandler) ConfirmMyMFAEnrollment(c *fiber.Ctx) error {
+	var req port.MFACodeReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	userID, _ := c.Locals(LocalUserID).(string)
+
+	res, err := h.svc.ConfirmMFAEnrollment(c.UserContext(), userID, req.Code)
+	if err != nil {
+		return respondMFAError(c, err)
+	}
+	return c.JSON(res)
+}
+
+func (h *AuthHandler) DisableMyMFA(c *fiber.Ctx) error {
+	var req port.MFACodeReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	userID, _ := c.Locals(LocalUserID).(string)
+
+	if err := h.svc.DisableMFA(c.UserContext(), userID, req.Code); err != nil {
+		return respondMFAError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "MFA disabled"})
+}
+
+func (h *AuthHandler) RegenerateMyRecoveryCodes(c *fiber.Ctx) error {
+	var req port.MFACodeReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	userID, _ := c.Locals(LocalUserID).(string)
+
+	res, err := h.svc.RegenerateRecoveryCodes(c.UserContext(), userID, req.Code)
+	if err != nil {
+		return respondMFAError(c, err)
+	}
+	return c.JSON(res)
+}
+
+// respondMFAError: Challenge / Code ผิด = 401, สถานะไม่ถูกต้อง = 409, ถูกบังคับ / ระงับ = 403
+func respondMFAError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrTooManyLoginAttempts):
+		return respondLoginLocked(c, err)
+	case errors.Is(err, domain.ErrInvalidMFAChallenge), errors.Is(err, domain.ErrInvalidMFACode):
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrMFAAlreadyEnabled), errors.Is(err, domain.ErrMFANotEnrolled):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrMFARequired), errors.Is(err, domain.ErrUserDisabled):
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
+
+// ChangePassword ใช้ Username + รหัสผ่านปัจจุบัน (ไม่ต้องมี Token) เพราะคนที่ถูกบังคับเปลี่ยนรหัส Login ไม่ได้
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	var req port.ChangePasswordReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+
+	req.IP = c.IP()
+
+	if err := h.svc.ChangePassword(c.UserContext(), &req); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTooManyLoginAttempts):
+			return respondLoginLocked(c, err)
+		case errors.Is(err, domain.ErrInvalidCredentials):
+			return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, domain.ErrUserDisabled):
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, domain.ErrValidation):
+			return respondValidationError(c, err)
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "password changed"})
+}
+
+// Logout ต้องผ่าน NewAuthMiddleware มาก่อน (ใช้ข้อมูล Token จาก c.Locals)
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req port.LogoutReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+		}
+	}
+	req.UserID, _ = c.Locals(LocalUserID).(string)
+	req.TokenID, _ = c.Locals(LocalTokenID).(string)
+	req.ExpiresAt, _ = c.Locals(LocalTokenExp).(time.Time)
+
+	if err := h.svc.Logout(c.UserContext(), &req); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "logged out"})
+}
+
+// respondValidationError 422 พร้อมข้อความแยกตาม Field เช่น {"fields": {"password": ["must contain a digit"]}}
+func respondValidationError(c *fiber.Ctx, err error) error {
+	var verr *domain.ValidationError
+	if !errors.As(err, &verr) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+		"error":  domain.ErrValidation.Error(),
+		"fields": verr.Fields,
+	})
+}
+
+// respondLoginLocked 429 พร้อม Retry-After (ข้อความเดียวกันเสมอ ไม่บอกว่า Username มีอยู่จริงหรือไม่)
+func respondLoginLocked(c *fiber.Ctx, err error) error {
+	var locked *port.LoginLockedError
+	if errors.As(err, &locked) && locked.RetryAfter > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+	}
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": domain.ErrTooManyLoginAttempts.Error()})
+}
+
+// UnlockUser (Admin) ปลดล็อกบัญชีที่ Login ผิดจนถูกล็อก
+func (h *AuthHandler) UnlockUser(c *fiber.Ctx) error {
+	if err := h.svc.UnlockAccount(c.UserContext(), c.Params("id")); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Account unlocked"})
+}
+
+// RevokeUserSessions (Admin) เพิกถอนทุก Session ของ User
+func (h *AuthHandler) RevokeUserSessions(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	if err := h.svc.RevokeAllSessions(c.UserContext(), userID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "All sessions revoked"})
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuthzHandler struct {
+	svc port.AuthzService
+}
+
+func NewAuthzHandler(svc port.AuthzService) *AuthzHandler {
+	return &AuthzHandler{svc: svc}
+}
+
+// Check ถามสิทธิ์ 1 รายการ: ตอบ 200 เสมอ (ดูผลที่ allowed / reason) ยกเว้น Request ผิดรูปแบบ
+func (h *AuthzHandler) Check(c *fiber.Ctx) error {
+	var req port.AuthzCheckReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	result, err := h.svc.Check(c.UserContext(), &req)
+	if err != nil {
+		return respondAuthzError(c, err)
+	}
+	return c.JSON(result)
+}
+
+// CheckBatch ถามหลายรายการของ Subject เดียว ผลเรียงตามลำดับของ checks
+func (h *AuthzHandler) CheckBatch(c *fiber.Ctx) error {
+	var req port.AuthzBatchReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	result, err := h.svc.CheckBatch(c.UserContext(), &req)
+	if err != nil {
+		return respondAuthzError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func respondAuthzError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, domain.ErrInvalidAuthzRequest) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": "Authorization failed"})
+}
@@ -0,0 +1,20 @@
+package http
+
+import (
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/gofiber/fiber/v2"
+)
+
+type JWKSHandler struct {
+	keySet port.KeySet
+}
+
+func NewJWKSHandler(keySet port.KeySet) *JWKSHandler {
+	return &JWKSHandler{keySet: keySet}
+}
+
+// GetJWKS เปิด Public Key ให้ Service อื่นใช้ตรวจ Token ของเราได้เอง (ไม่ต้องถือ Secret ร่วมกัน)
+func (h *JWKSHandler) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.keySet.JWKS())
+}
@@ -1,53 +1,338 @@
 package http
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
 	"strings"
+	"time"
 
-	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// Key ของ c.Locals ที่ Middleware ตั้งไว้ให้ Handler ถัดไปใช้
+const (
+	LocalUserID   = "user_id"
+	LocalTokenID  = "token_id"
+	LocalTokenExp = "token_exp"
+	LocalTenantID = "tenant_id"
+	LocalClientID = "client_id" // Service ที่เรียก Decision API
+
+	// LocalResourceOwner ให้ Middleware ก่อนหน้า (ที่โหลด Resource) ตั้ง uid เจ้าของไว้
+	// ใช้กับ Condition `resource.owner == subject`
+	LocalResourceOwner = "resource_owner"
+
+	// HeaderAPIKey ใช้แทน Bearer Token สำหรับ Service Account
+	HeaderAPIKey = "X-API-Key"
+
+	// HeaderTenantID เลือก Tenant ต่อ Request (ใช้ได้เมื่อ Token ไม่ได้ผูก Tenant ไว้ หรือผูกไว้ตรงกัน)
+	HeaderTenantID = "X-Tenant-ID"
+
+	// HeaderRequestID รับจาก Gateway ได้ (ถ้าไม่มีหรือผิดรูปแบบจะสร้างใหม่) และส่งกลับใน Response เสมอ
+	HeaderRequestID = "X-Request-ID"
+	maxRequestIDLen = 128
+)
+
+// NewRequestMetaMiddleware ใส่ Request ID / IP ลง c.UserContext() ให้ Audit Log ใช้
+// ต้องลงทะเบียนก่อน Route ทั้งหมด (Middleware ยืนยันตัวตนจะเติม Actor ให้ทีหลัง)
+func NewRequestMetaMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set(HeaderRequestID, requestID)
+
+		meta := &port.RequestMeta{RequestID: requestID, IP: c.IP()}
+		c.SetUserContext(port.WithRequestMeta(c.UserContext(), meta))
+		return c.Next()
+	}
+}
+
+// NewAuthMiddleware ตรวจแค่ว่า Token ใช้ได้และยังไม่ถูกเพิกถอน (ไม่เช็ค Permission)
+// ใช้กับ Route ที่แค่ต้อง Login เช่น /auth/logout
+func NewAuthMiddleware(verifier port.TokenVerifier, apiKeys port.APIKeyAuthenticator, authSvc port.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := authenticate(c, verifier, apiKeys, authSvc); err != nil {
+			return respondAuthError(c, err)
+		}
+		return c.Next()
+	}
+}
+
 // Factory function เพื่อสร้าง Middleware
-func NewRBACMiddleware(cfg *config.Config, rbacSvc port.RBACService) func(perm string) fiber.Handler {
+// ทุก Route ที่ต้องป้องกันใช้ authenticate ตัวเดียวกันเสมอ
+func NewRBACMiddleware(verifier port.TokenVerifier, apiKeys port.APIKeyAuthenticator, rbacSvc port.RBACService, authSvc port.AuthService) func(perm string) fiber.Handler {
 	return func(requiredPerm string) fiber.Handler {
 		return func(c *fiber.Ctx) error {
-			// 1. ดึง Token จาก Header
-			authHeader := c.Get("Authorization")
-			if authHeader == "" {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Missing Authorization header"})
+			// 1-3. ตรวจ Token (หรือ API Key) + Denylist
+			if err := authenticate(c, verifier, apiKeys, authSvc); err != nil {
+				return respondAuthError(c, err)
 			}
-			tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
+			userID := c.Locals(LocalUserID).(string)
 
-			// 2. Parse Token
-			token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-				return []byte(cfg.Server.JWTSecret), nil
-			})
+			// 3.5 เลือก Tenant ของ Request (ถ้ามี)
+			ctx, err := resolveTenant(c, rbacSvc)
+			if err != nil {
+				return respondTenantError(c, err)
+			}
 
-			if err != nil || !token.Valid {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid Token"})
+			// 4. เช็คสิทธิ์กับ RBAC Service (พร้อม Attributes สำหรับ Conditional Grant)
+			allow, err := rbacSvc.CheckAccess(withRequestAttributes(ctx, c), userID, requiredPerm)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Authorization failed"})
 			}
 
-			// 3. ดึง User ID จาก Claims
-			claims, ok := token.Claims.(jwt.MapClaims)
-			if !ok {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid Claims"})
+			if !allow {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Access Denied: You don't have permission " + requiredPerm})
 			}
-			userID := claims["user_id"].(string)
 
-			// 4. เช็คสิทธิ์กับ RBAC Service
-			allow, err := rbacSvc.CheckAccess(userID, requiredPerm)
+			// ผ่านฉลุย
+			return c.Next()
+		}
+	}
+}
+
+// NewResourceRBACMiddleware เหมือน NewRBACMiddleware แต่เช็คสิทธิ์บน Resource ที่ระบุใน URL
+// เช่น guardOn("document:edit", "document", "id") กับ Route /documents/:id
+func NewResourceRBACMiddleware(verifier port.TokenVerifier, apiKeys port.APIKeyAuthenticator, rbacSvc port.RBACService, authSvc port.AuthService) func(perm string, resourceType string, param string) fiber.Handler {
+	return func(requiredPerm string, resourceType string, param string) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			if err := authenticate(c, verifier, apiKeys, authSvc); err != nil {
+				return respondAuthError(c, err)
+			}
+			userID := c.Locals(LocalUserID).(string)
+
+			resourceID := c.Params(param)
+			if resourceID == "" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing resource id"})
+			}
+			resource := port.Resource{Type: resourceType, ID: resourceID}
+
+			ctx, err := resolveTenant(c, rbacSvc)
+			if err != nil {
+				return respondTenantError(c, err)
+			}
+
+			allow, err := rbacSvc.CheckAccessOn(withRequestAttributes(ctx, c), userID, requiredPerm, resource)
 			if err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Authorization failed"})
 			}
 
 			if !allow {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Access Denied: You don't have permission " + requiredPerm})
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Access Denied: You don't have permission " + requiredPerm + " on " + resourceType + " " + resourceID})
 			}
 
-			// ผ่านฉลุย
 			return c.Next()
 		}
 	}
 }
+
+// NewServiceClientMiddleware ตรวจ Service ที่เรียก Decision API ด้วย HTTP Basic (client_id:secret)
+// แยกจาก Token ของ User โดยสิ้นเชิง
+func NewServiceClientMiddleware(clientAuth port.ClientAuthenticator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		clientID, secret, ok := basicCredentials(c.Get(fiber.HeaderAuthorization))
+		if !ok || clientAuth.Authenticate(clientID, secret) != nil {
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="authz"`)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": domain.ErrInvalidClientCredentials.Error()})
+		}
+		c.Locals(LocalClientID, clientID)
+		setAuditActor(c, clientID, domain.AuditActorClient)
+		return c.Next()
+	}
+}
+
+// authenticate ตรวจ Token แล้วเก็บข้อมูลลง c.Locals
+// คืน domain.ErrToken* ให้ผู้เรียกแปลงเป็น Response เอง
+func authenticate(c *fiber.Ctx, verifier port.TokenVerifier, apiKeys port.APIKeyAuthenticator, authSvc port.AuthService) error {
+	// 0. ไม่มี Authorization แต่มี X-API-Key = Service Account
+	if c.Get(fiber.HeaderAuthorization) == "" && c.Get(HeaderAPIKey) != "" {
+		return authenticateAPIKey(c, apiKeys, authSvc)
+	}
+
+	// 1. ดึง Token จาก Header (ต้องเป็น Bearer เท่านั้น)
+	tokenString, ok := bearerToken(c.Get(fiber.HeaderAuthorization))
+	if !ok {
+		return domain.ErrTokenMissing
+	}
+
+	// 2. ตรวจ Signature, Algorithm, exp / iat / iss / aud
+	claims, err := verifier.Verify(tokenString)
+	if err != nil {
+		return err
+	}
+
+	// 3. Token ถูก Logout / Revoke ไปแล้วหรือยัง (เช็คก่อน CheckAccess)
+	revoked, err := authSvc.IsTokenRevoked(c.UserContext(), claims.UserID, claims.TokenID, claims.IssuedAt)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return domain.ErrTokenRevoked
+	}
+
+	// 4. บัญชีถูกระงับ / ลบ: Token ที่ยังไม่หมดอายุก็ใช้ไม่ได้
+	disabled, err := authSvc.IsUserDisabled(c.UserContext(), claims.UserID)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		return domain.ErrUserDisabled
+	}
+
+	c.Locals(LocalUserID, claims.UserID)
+	c.Locals(LocalTokenID, claims.TokenID)
+	c.Locals(LocalTokenExp, claims.ExpiresAt)
+	c.Locals(LocalTenantID, claims.TenantID)
+	setAuditActor(c, claims.UserID, domain.AuditActorUser)
+	return nil
+}
+
+// authenticateAPIKey ตั้ง c.Locals แบบเดียวกับ Token (ไม่มี jti / exp / tid: Tenant เลือกผ่าน Header ได้)
+func authenticateAPIKey(c *fiber.Ctx, apiKeys port.APIKeyAuthenticator, authSvc port.AuthService) error {
+	userID, err := apiKeys.AuthenticateAPIKey(c.UserContext(), c.Get(HeaderAPIKey))
+	if err != nil {
+		return err
+	}
+
+	disabled, err := authSvc.IsUserDisabled(c.UserContext(), userID)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		return domain.ErrUserDisabled
+	}
+
+	c.Locals(LocalUserID, userID)
+	c.Locals(LocalTokenID, "")
+	c.Locals(LocalTokenExp, time.Time{})
+	c.Locals(LocalTenantID, "")
+	setAuditActor(c, userID, domain.AuditActorService)
+	return nil
+}
+
+// setAuditActor เติม Actor ลง Request Meta (ถ้ามี NewRequestMetaMiddleware อยู่ก่อนหน้า)
+func setAuditActor(c *fiber.Ctx, actorID string, actorType string) {
+	if meta := port.RequestMetaFromContext(c.UserContext()); meta != nil {
+		meta.ActorID = actorID
+		meta.ActorType = actorType
+	}
+}
+
+// resolveTenant หา Tenant ของ Request แล้วใส่ลง Context สำหรับ CheckAccess
+//   - Token ผูก Tenant ไว้ (tid): Header ต้องว่างหรือตรงกัน (เป็นสมาชิกแล้วตั้งแต่ตอน Login)
+//   - Token ไม่ได้ผูก: ใช้ Header ได้ แต่ต้องเป็นสมาชิกของ Tenant นั้น
+func resolveTenant(c *fiber.Ctx, rbacSvc port.RBACService) (context.Context, error) {
+	ctx := c.UserContext()
+	tokenTenant, _ := c.Locals(LocalTenantID).(string)
+	headerTenant := c.Get(HeaderTenantID)
+
+	switch {
+	case tokenTenant != "":
+		if headerTenant != "" && headerTenant != tokenTenant {
+			return nil, domain.ErrTenantMismatch
+		}
+		return port.WithTenant(ctx, tokenTenant), nil
+
+	case headerTenant != "":
+		userID := c.Locals(LocalUserID).(string)
+		member, err := rbacSvc.IsTenantMember(ctx, headerTenant, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			return nil, domain.ErrNotTenantMember
+		}
+		c.Locals(LocalTenantID, headerTenant)
+		return port.WithTenant(ctx, headerTenant), nil
+	}
+
+	return ctx, nil
+}
+
+// withRequestAttributes ดึงข้อมูลของ Request ที่ Condition ใช้ได้ (IP, เวลา, เจ้าของ Resource)
+func withRequestAttributes(ctx context.Context, c *fiber.Ctx) context.Context {
+	owner, _ := c.Locals(LocalResourceOwner).(string)
+	return port.WithAttributes(ctx, port.AccessAttributes{
+		IP:            c.IP(),
+		Time:          time.Now(),
+		ResourceOwner: owner,
+	})
+}
+
+func respondTenantError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, domain.ErrTenantMismatch) || errors.Is(err, domain.ErrNotTenantMember) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Authorization failed"})
+}
+
+// validRequestID รับเฉพาะตัวอักษรที่ปลอดภัยต่อการเขียนลง Log (กัน Header Injection)
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func basicCredentials(header string) (string, string, bool) {
+	scheme, encoded, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Basic") {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", "", false
+	}
+	clientID, secret, found := strings.Cut(string(decoded), ":")
+	return clientID, secret, found && clientID != "" && secret != ""
+}
+
+// authErrorCodes: Error แต่ละชนิดได้ 401 พร้อม code ของตัวเอง ให้ Client แยกได้ว่าต้อง Refresh หรือ Login ใหม่
+var authErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{domain.ErrTokenMissing, "token_missing"},
+	{domain.ErrTokenMalformed, "token_malformed"},
+	{domain.ErrTokenAlgorithm, "token_algorithm_not_allowed"},
+	{domain.ErrTokenUnknownKey, "token_unknown_key"},
+	{domain.ErrTokenSignatureInvalid, "token_signature_invalid"},
+	{domain.ErrTokenExpired, "token_expired"},
+	{domain.ErrTokenNotYetValid, "token_not_yet_valid"},
+	{domain.ErrTokenClaimsInvalid, "token_claims_invalid"},
+	{domain.ErrTokenRevoked, "token_revoked"},
+	{domain.ErrUserDisabled, "account_disabled"},
+	{domain.ErrInvalidAPIKey, "api_key_invalid"},
+}
+
+func respondAuthError(c *fiber.Ctx, err error) error {
+	for _, e := range authErrorCodes {
+		if errors.Is(err, e.err) {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": e.err.Error(), "code": e.code})
+		}
+	}
+	// Error อื่นๆ (เช่น Denylist ใช้งานไม่ได้) ไม่ใช่ความผิดของ Client
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Authorization failed"})
+}
@@ -1,6 +1,17 @@
 package http
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
 	"github.com/gofiber/fiber/v2"
 )
@@ -18,19 +29,103 @@ func (h *RBACHandler) CreateRole(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
 	}
-	if err := h.svc.CreateRole(&req); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	if err := h.svc.CreateRole(c.UserContext(), &req); err != nil {
+		return respondCatalogError(c, err)
 	}
 	return c.JSON(fiber.Map{"message": "Role created"})
 }
 
+// UpdateRole เปลี่ยนชื่อ / คำอธิบาย (ส่งเฉพาะ Field ที่จะเปลี่ยน)
+func (h *RBACHandler) UpdateRole(c *fiber.Ctx) error {
+	var req port.UpdateRoleReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	role, err := h.svc.UpdateRole(c.UserContext(), c.Params("id"), &req)
+	if err != nil {
+		return respondCatalogError(c, err)
+	}
+	return c.JSON(toRoleResponse(role))
+}
+
+// DeleteRole Soft Delete (?cascade=true ลบ Assignment / Permission / Parent ที่ผูกอยู่ทิ้งถาวร)
+func (h *RBACHandler) DeleteRole(c *fiber.Ctx) error {
+	if err := h.svc.DeleteRole(c.UserContext(), c.Params("id"), c.QueryBool("cascade")); err != nil {
+		return respondCatalogError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Role deleted"})
+}
+
+func (h *RBACHandler) RestoreRole(c *fiber.Ctx) error {
+	if err := h.svc.RestoreRole(c.UserContext(), c.Params("id")); err != nil {
+		return respondCatalogError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Role restored"})
+}
+
+func (h *RBACHandler) GetDeletedRoles(c *fiber.Ctx) error {
+	roles, err := h.svc.GetDeletedRoles(c.UserContext())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toRoleResponses(roles))
+}
+
+func (h *RBACHandler) UpdatePermission(c *fiber.Ctx) error {
+	var req port.UpdatePermReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	perm, err := h.svc.UpdatePermission(c.UserContext(), c.Params("id"), &req)
+	if err != nil {
+		return respondCatalogError(c, err)
+	}
+	return c.JSON(toPermissionResponse(perm))
+}
+
+// DeletePermission Soft Delete (?cascade=true ลบการผูกกับ Role ทิ้งถาวร)
+func (h *RBACHandler) DeletePermission(c *fiber.Ctx) error {
+	if err := h.svc.DeletePermission(c.UserContext(), c.Params("id"), c.QueryBool("cascade")); err != nil {
+		return respondCatalogError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Permission deleted"})
+}
+
+func (h *RBACHandler) RestorePermission(c *fiber.Ctx) error {
+	if err := h.svc.RestorePermission(c.UserContext(), c.Params("id")); err != nil {
+		return respondCatalogError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Permission restored"})
+}
+
+func (h *RBACHandler) GetDeletedPermissions(c *fiber.Ctx) error {
+	perms, err := h.svc.GetDeletedPermissions(c.UserContext())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toPermissionResponses(perms))
+}
+
+// respondCatalogError: ไม่พบ = 404, ชื่อซ้ำ = 409, ชื่อผิดรูปแบบ = 400
+func respondCatalogError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrAlreadyExists):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidRoleName), errors.Is(err, domain.ErrInvalidPermissionName):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
+
 func (h *RBACHandler) CreatePermission(c *fiber.Ctx) error {
 	var req port.CreatePermReq
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
 	}
-	if err := h.svc.CreatePermission(&req); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	if err := h.svc.CreatePermission(c.UserContext(), &req); err != nil {
+		return respondCatalogError(c, err)
 	}
 	return c.JSON(fiber.Map{"message": "Permission created"})
 }
@@ -40,7 +135,10 @@ func (h *RBACHandler) AssignPermission(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
 	}
-	if err := h.svc.AssignPermissionToRole(&req); err != nil {
+	if err := h.svc.AssignPermissionToRole(c.UserContext(), &req); err != nil {
+		if errors.Is(err, domain.ErrInvalidCondition) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(fiber.Map{"message": "Permission assigned to Role"})
@@ -51,7 +149,10 @@ func (h *RBACHandler) AssignRole(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
 	}
-	if err := h.svc.AssignRoleToUser(&req); err != nil {
+	if err := h.svc.AssignRoleToUser(c.UserContext(), &req); err != nil {
+		if errors.Is(err, domain.ErrInvalidValidityWindow) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(fiber.Map{"message": "Role assigned to User"})
@@ -62,7 +163,7 @@ func (h *RBACHandler) RemovePermission(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
 	}
-	if err := h.svc.RemovePermissionFromRole(&req); err != nil {
+	if err := h.svc.RemovePermissionFromRole(c.UserContext(), &req); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(fiber.Map{"message": "Permission removed from Role"})
@@ -73,26 +174,85 @@ func (h *RBACHandler) RemoveRole(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
 	}
-	if err := h.svc.RemoveRoleFromUser(&req); err != nil {
+	if err := h.svc.RemoveRoleFromUser(c.UserContext(), &req); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(fiber.Map{"message": "Role removed from User"})
 }
 
+func (h *RBACHandler) AssignParent(c *fiber.Ctx) error {
+	var req port.AssignParentReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	if err := h.svc.AssignParentToRole(c.UserContext(), &req); err != nil {
+		if errors.Is(err, domain.ErrRoleCycle) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Parent assigned to Role"})
+}
+
+func (h *RBACHandler) RemoveParent(c *fiber.Ctx) error {
+	var req port.UnassignParentReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	if err := h.svc.RemoveParentFromRole(c.UserContext(), &req); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Parent removed from Role"})
+}
+
+func (h *RBACHandler) BindResourceRole(c *fiber.Ctx) error {
+	var req port.BindResourceRoleReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	if err := h.svc.BindRoleOnResource(c.UserContext(), &req); err != nil {
+		if errors.Is(err, domain.ErrValidation) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Role bound to User on resource"})
+}
+
+func (h *RBACHandler) UnbindResourceRole(c *fiber.Ctx) error {
+	var req port.UnbindResourceRoleReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	if err := h.svc.UnbindRoleOnResource(c.UserContext(), &req); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Role unbound from User on resource"})
+}
+
 func (h *RBACHandler) GetRoles(c *fiber.Ctx) error {
 	roles, err := h.svc.GetAllRoles()
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
-	return c.JSON(roles)
+	return c.JSON(toRoleResponses(roles))
 }
 
 func (h *RBACHandler) GetPermissions(c *fiber.Ctx) error {
+	// ?expand=true แสดงว่า Wildcard แต่ละตัวครอบคลุม Permission ไหนบ้าง
+	if c.QueryBool("expand") {
+		perms, err := h.svc.GetAllPermissionsExpanded()
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(toExpandedPermissionResponses(perms))
+	}
+
 	perms, err := h.svc.GetAllPermissions()
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
-	return c.JSON(perms)
+	return c.JSON(toPermissionResponses(perms))
 }
 
 func (h *RBACHandler) GetUserRoles(c *fiber.Ctx) error {
@@ -101,5 +261,282 @@ func (h *RBACHandler) GetUserRoles(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
-	return c.JSON(roles)
+	return c.JSON(toRoleResponses(roles))
+}
+
+// GetUserPermissions (Admin) สิทธิ์ทั้งหมดของ User พร้อม Role ที่มา (?tenant_id= เพื่อรวม Role ของ Tenant)
+func (h *RBACHandler) GetUserPermissions(c *fiber.Ctx) error {
+	ctx := port.WithTenant(c.UserContext(), c.Query("tenant_id"))
+	perms, err := h.svc.GetEffectivePermissions(ctx, c.Params("id"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return respondWithETag(c, perms)
+}
+
+// MyPermissions สิทธิ์ของคนที่เรียก (ใช้ซ่อน/แสดง UI) ต้องผ่าน NewAuthMiddleware มาก่อน
+// Tenant เลือกแบบเดียวกับ guard (Token หรือ Header X-Tenant-ID)
+func (h *RBACHandler) MyPermissions(c *fiber.Ctx) error {
+	ctx, err := resolveTenant(c, h.svc)
+	if err != nil {
+		return respondTenantError(c, err)
+	}
+	userID, _ := c.Locals(LocalUserID).(string)
+	perms, err := h.svc.GetEffectivePermissions(ctx, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return respondWithETag(c, perms)
+}
+
+// respondWithETag ตอบ 304 ถ้า Client มีข้อมูลชุดเดียวกันอยู่แล้ว (If-None-Match)
+func respondWithETag(c *fiber.Ctx, body any) error {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	sum := sha256.Sum256(encoded)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	if match := c.Get(fiber.HeaderIfNoneMatch); match == etag || match == "W/"+etag {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(encoded)
+}
+
+// GetPermissionHolders (Who Can) ใครถือ Permission นี้บ้าง
+// GET /permissions/:name/holders?q=&role=&scope=platform|tenant|binding&tenant_id=&page=1&page_size=50[&format=csv]
+// format=csv จะ Export ทุกแถวที่ตรง Filter (ไม่แบ่งหน้า)
+func (h *RBACHandler) GetPermissionHolders(c *fiber.Ctx) error {
+	perm, err := url.PathUnescape(c.Params("name"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad permission name"})
+	}
+	asCSV := c.Query("format") == "csv"
+
+	query := port.HoldersQuery{
+		Permission: perm,
+		Search:     c.Query("q"),
+		Role:       c.Query("role"),
+		Scope:      c.Query("scope"),
+		TenantID:   c.Query("tenant_id"),
+		Page:       c.QueryInt("page", 1),
+		PageSize:   c.QueryInt("page_size", 50),
+	}
+	if query.PageSize > maxPageSize {
+		query.PageSize = maxPageSize
+	}
+	if asCSV {
+		query.Page, query.PageSize = 1, 0
+	}
+
+	holders, err := h.svc.GetPermissionHolders(c.UserContext(), &query)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPermissionName) || errors.Is(err, domain.ErrInvalidQuery) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if asCSV {
+		return writeHoldersCSV(c, holders)
+	}
+	return c.JSON(holders)
+}
+
+// maxPageSize กันดึงทีละมากเกินไปใน Endpoint แบบ List
+const maxPageSize = 200
+
+// writeHoldersCSV 1 แถวต่อ 1 Grant (User ที่ได้สิทธิ์หลายทางจะมีหลายแถว)
+func writeHoldersCSV(c *fiber.Ctx, holders *port.PermissionHolders) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"user_id", "username", "email", "role", "scope", "tenant_id", "resource_type", "resource_id", "path", "condition"})
+	for _, u := range holders.Users {
+		for _, g := range u.Grants {
+			var resourceType, resourceID string
+			if g.Resource != nil {
+				resourceType, resourceID = g.Resource.Type, g.Resource.ID
+			}
+			row := []string{u.UserID, u.Username, u.Email, g.Role, g.Scope, g.TenantID, resourceType, resourceID, strings.Join(g.Path, " > "), g.Condition}
+			for i := range row {
+				row[i] = csvSafe(row[i])
+			}
+			_ = w.Write(row)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	filename := strings.NewReplacer(":", "_", "*", "all").Replace(holders.Permission)
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="holders-`+filename+`.csv"`)
+	return c.Send(buf.Bytes())
+}
+
+// csvSafe กัน CSV Injection: Excel / Sheets ตีความ Cell ที่ขึ้นต้นด้วยอักขระเหล่านี้เป็นสูตร
+// (Username / Email / Resource ID มาจาก User) จึงเติม ' นำหน้าให้เป็นข้อความธรรมดา
+func csvSafe(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func (h *RBACHandler) GetUserBindings(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	bindings, err := h.svc.GetUserBindings(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toResourceBindingResponses(bindings))
+}
+
+// Explain ตอบว่าทำไม User ถึงได้/ไม่ได้สิทธิ์
+// GET /users/:id/explain?permission=dashboard:view[&resource_type=project&resource_id=42][&tenant_id=...][&ip=...][&resource_owner=...]
+func (h *RBACHandler) Explain(c *fiber.Ctx) error {
+	perm := c.Query("permission")
+	if perm == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "permission is required"})
+	}
+
+	var resource *port.Resource
+	if resourceType, resourceID := c.Query("resource_type"), c.Query("resource_id"); resourceType != "" || resourceID != "" {
+		if resourceType == "" || resourceID == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "resource_type and resource_id must be given together"})
+		}
+		resource = &port.Resource{Type: resourceType, ID: resourceID}
+	}
+
+	// จำลอง Request ของ User (Tenant + Attributes สำหรับ Conditional Grant)
+	ctx := port.WithTenant(c.UserContext(), c.Query("tenant_id"))
+	ctx = port.WithAttributes(ctx, port.AccessAttributes{
+		IP:            c.Query("ip"),
+		Time:          time.Now(),
+		ResourceOwner: c.Query("resource_owner"),
+	})
+
+	explanation, err := h.svc.Explain(ctx, c.Params("id"), perm, resource)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(explanation)
+}
+
+func (h *RBACHandler) CreateTenant(c *fiber.Ctx) error {
+	var req port.CreateTenantReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	if err := h.svc.CreateTenant(c.UserContext(), &req); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Tenant created"})
+}
+
+func (h *RBACHandler) GetTenants(c *fiber.Ctx) error {
+	tenants, err := h.svc.GetAllTenants()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toTenantResponses(tenants))
+}
+
+func (h *RBACHandler) AssignTenantRole(c *fiber.Ctx) error {
+	var req port.AssignTenantRoleReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	if err := h.svc.AssignTenantRole(c.UserContext(), &req); err != nil {
+		if errors.Is(err, domain.ErrInvalidValidityWindow) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Role assigned to User in Tenant"})
+}
+
+func (h *RBACHandler) RemoveTenantRole(c *fiber.Ctx) error {
+	var req port.UnassignTenantRoleReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	if err := h.svc.RemoveTenantRole(c.UserContext(), &req); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Role removed from User in Tenant"})
+}
+
+func (h *RBACHandler) GetUserTenantRoles(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	assignments, err := h.svc.GetUserTenantRoles(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toTenantRoleResponses(assignments))
+}
+
+func (h *RBACHandler) AddRoleDeny(c *fiber.Ctx) error {
+	var req port.RoleDenyReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	if err := h.svc.AddRoleDeny(c.UserContext(), &req); err != nil {
+		if errors.Is(err, domain.ErrInvalidPermissionName) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Deny rule added to Role"})
+}
+
+func (h *RBACHandler) RemoveRoleDeny(c *fiber.Ctx) error {
+	var req port.RoleDenyReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	if err := h.svc.RemoveRoleDeny(c.UserContext(), &req); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Deny rule removed from Role"})
+}
+
+func (h *RBACHandler) AddUserDeny(c *fiber.Ctx) error {
+	var req port.UserDenyReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	if err := h.svc.AddUserDeny(c.UserContext(), &req); err != nil {
+		if errors.Is(err, domain.ErrInvalidPermissionName) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Deny rule added to User"})
+}
+
+func (h *RBACHandler) RemoveUserDeny(c *fiber.Ctx) error {
+	var req port.UserDenyReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+	if err := h.svc.RemoveUserDeny(c.UserContext(), &req); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Deny rule removed from User"})
+}
+
+func (h *RBACHandler) GetUserDenies(c *fiber.Ctx) error {
+	userID := c.Params("id")
+	rules, err := h.svc.GetUserDenies(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toUserDenyResponses(rules))
 }
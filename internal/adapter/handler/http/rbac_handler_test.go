@@ -0,0 +1,61 @@
+package http
+
+import (
+	"encoding/csv"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestWriteHoldersCSVEscapesFormulas(t *testing.T) {
+	holders := &port.PermissionHolders{
+		Permission: "report:view",
+		Users: []port.PermissionHolder{{
+			UserID:   "u-1",
+			Username: "=HYPERLINK(\"http://evil.example\")",
+			Email:    "+1@example.com",
+			Grants: []port.HolderGrant{{
+				Role:     "viewer",
+				Scope:    port.RoleOriginBinding,
+				Resource: &port.Resource{Type: "project", ID: "-42"},
+				Path:     []string{"viewer"},
+			}, {
+				Role:      "auditor",
+				Scope:     port.RoleOriginPlatform,
+				Path:      []string{"auditor"},
+				Condition: "@SUM(A1)",
+			}},
+		}},
+	}
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error { return writeHoldersCSV(c, holders) })
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d rows, want header + 2", len(records))
+	}
+
+	first, second := records[1], records[2]
+	checks := []struct{ got, want string }{
+		{first[0], "u-1"},
+		{first[1], "'=HYPERLINK(\"http://evil.example\")"},
+		{first[2], "'+1@example.com"},
+		{first[3], "viewer"},
+		{first[7], "'-42"},
+		{second[9], "'@SUM(A1)"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("cell = %q, want %q", c.got, c.want)
+		}
+	}
+}
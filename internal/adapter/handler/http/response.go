@@ -0,0 +1,206 @@
+package http
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// --- Domain -> Response DTO ---
+// ทุก Handler ที่ส่ง Domain Object (User / Role / Permission / Binding / Tenant / Deny) ออกไปต้องผ่านฟังก์ชันในไฟล์นี้
+
+func toUserResponse(u *domain.User) port.UserResponse {
+	return port.UserResponse{
+		ID:                 u.Uid.String(),
+		Username:           u.Username,
+		Email:              u.Email,
+		Kind:               u.Kind,
+		Description:        u.Description,
+		EmailVerifiedAt:    u.EmailVerifiedAt,
+		DisabledAt:         u.DisabledAt,
+		MustChangePassword: u.MustChangePassword,
+		CreatedAt:          u.CreatedAt,
+		UpdatedAt:          u.UpdatedAt,
+		DeletedAt:          deletedAt(u.DeletedAt),
+	}
+}
+
+// toAPIKeyResponse ไม่ส่ง Hash ออกไป (Key ตัวจริงใส่เองใน CreatedAPIKeyResponse ตอนสร้าง / Rotate)
+func toAPIKeyResponse(k *domain.APIKey) port.APIKeyResponse {
+	return port.APIKeyResponse{
+		ID:         k.Uid.String(),
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt,
+		RevokedAt:  k.RevokedAt,
+		LastUsedAt: k.LastUsedAt,
+	}
+}
+
+func toPermissionResponse(p *domain.Permission) port.PermissionResponse {
+	return port.PermissionResponse{
+		ID:          p.Uid.String(),
+		Name:        p.Name,
+		Description: p.Description,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		DeletedAt:   deletedAt(p.DeletedAt),
+	}
+}
+
+func toPermissionResponses(perms []domain.Permission) []port.PermissionResponse {
+	res := make([]port.PermissionResponse, 0, len(perms))
+	for i := range perms {
+		res = append(res, toPermissionResponse(&perms[i]))
+	}
+	return res
+}
+
+func toExpandedPermissionResponses(perms []port.ExpandedPermission) []port.ExpandedPermissionResponse {
+	res := make([]port.ExpandedPermissionResponse, 0, len(perms))
+	for i := range perms {
+		res = append(res, port.ExpandedPermissionResponse{
+			PermissionResponse: toPermissionResponse(&perms[i].Permission),
+			Covers:             perms[i].Covers,
+		})
+	}
+	return res
+}
+
+// toRoleResponse: Condition ของแต่ละ Permission อยู่ใน PermissionLinks (ถ้า Repository Preload มา)
+func toRoleResponse(r *domain.Role) port.RoleResponse {
+	conditions := make(map[string]string, len(r.PermissionLinks))
+	for _, link := range r.PermissionLinks {
+		conditions[link.PermissionUid.String()] = link.Condition
+	}
+
+	res := port.RoleResponse{
+		ID:          r.Uid.String(),
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: make([]port.RolePermissionResponse, 0, len(r.Permissions)),
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+		DeletedAt:   deletedAt(r.DeletedAt),
+	}
+	for _, p := range r.Permissions {
+		res.Permissions = append(res.Permissions, port.RolePermissionResponse{
+			ID:        p.Uid.String(),
+			Name:      p.Name,
+			Condition: conditions[p.Uid.String()],
+		})
+	}
+	for _, parent := range r.Parents {
+		res.Parents = append(res.Parents, port.RoleRef{ID: parent.Uid.String(), Name: parent.Name})
+	}
+	for _, deny := range r.Denies {
+		res.Denies = append(res.Denies, deny.Permission)
+	}
+	return res
+}
+
+func toRoleResponses(roles []domain.Role) []port.RoleResponse {
+	res := make([]port.RoleResponse, 0, len(roles))
+	for i := range roles {
+		res = append(res, toRoleResponse(&roles[i]))
+	}
+	return res
+}
+
+// roleRef ใช้ชื่อจาก Role ที่ Preload มา (ถ้าไม่มีส่งแค่ ID)
+func roleRef(roleID uuid.UUID, r *domain.Role) port.RoleRef {
+	ref := port.RoleRef{ID: roleID.String()}
+	if r != nil {
+		ref.Name = r.Name
+	}
+	return ref
+}
+
+func toResourceBindingResponses(bindings []domain.ResourceRoleBinding) []port.ResourceBindingResponse {
+	res := make([]port.ResourceBindingResponse, 0, len(bindings))
+	for _, b := range bindings {
+		res = append(res, port.ResourceBindingResponse{
+			ID:           b.Uid.String(),
+			UserID:       b.UserUid.String(),
+			Role:         roleRef(b.RoleUid, b.Role),
+			ResourceType: b.ResourceType,
+			ResourceID:   b.ResourceID,
+			CreatedAt:    b.CreatedAt,
+		})
+	}
+	return res
+}
+
+func toTenantResponses(tenants []domain.Tenant) []port.TenantResponse {
+	res := make([]port.TenantResponse, 0, len(tenants))
+	for _, t := range tenants {
+		res = append(res, port.TenantResponse{ID: t.Uid.String(), Name: t.Name, CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt})
+	}
+	return res
+}
+
+func toTenantRoleResponses(assignments []domain.TenantRoleAssignment) []port.TenantRoleResponse {
+	res := make([]port.TenantRoleResponse, 0, len(assignments))
+	for _, a := range assignments {
+		item := port.TenantRoleResponse{
+			ID:         a.Uid.String(),
+			TenantID:   a.TenantUid.String(),
+			UserID:     a.UserUid.String(),
+			Role:       roleRef(a.RoleUid, a.Role),
+			ValidFrom:  a.ValidFrom,
+			ValidUntil: a.ValidUntil,
+			CreatedAt:  a.CreatedAt,
+		}
+		if a.Tenant != nil {
+			item.TenantName = a.Tenant.Name
+		}
+		res = append(res, item)
+	}
+	return res
+}
+
+func toUserDenyResponses(rules []domain.UserDenyRule) []port.UserDenyResponse {
+	res := make([]port.UserDenyResponse, 0, len(rules))
+	for _, r := range rules {
+		res = append(res, port.UserDenyResponse{ID: r.Uid.String(), UserID: r.UserUid.String(), Permission: r.Permission, CreatedAt: r.CreatedAt})
+	}
+	return res
+}
+
+func deletedAt(d gorm.DeletedAt) *time.Time {
+	if !d.Valid {
+		return nil
+	}
+	return &d.Time
+}
+
+// toAuditEventResponse ส่ง Before / After เป็น JSON ตามที่เก็บไว้ (ไม่ Decode ใหม่ จะได้เทียบกับ Hash ได้ตรงตัว)
+func toAuditEventResponse(e *domain.AuditEvent) port.AuditEventResponse {
+	res := port.AuditEventResponse{
+		Seq:        e.Seq,
+		OccurredAt: e.OccurredAt,
+		ActorID:    e.ActorID,
+		ActorType:  e.ActorType,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Outcome:    e.Outcome,
+		TenantID:   e.TenantID,
+		IP:         e.IP,
+		RequestID:  e.RequestID,
+		PrevHash:   e.PrevHash,
+		Hash:       e.Hash,
+	}
+	if e.Before != "" {
+		res.Before = json.RawMessage(e.Before)
+	}
+	if e.After != "" {
+		res.After = json.RawMessage(e.After)
+	}
+	return res
+}
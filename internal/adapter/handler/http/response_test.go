@@ -0,0 +1,171 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeUserService / fakeRBACService คืน Domain Object ที่มี Password Hash จริงเสมอ
+// Method ที่ไม่ได้ Implement จะ Panic (Embed Interface ไว้เฉยๆ)
+type fakeUserService struct {
+	port.UserService
+	user domain.User
+}
+
+func (f *fakeUserService) ListUsers(context.Context, *port.UserListQuery) ([]domain.User, int64, error) {
+	return []domain.User{f.user}, 1, nil
+}
+
+func (f *fakeUserService) GetUser(context.Context, string) (*domain.User, error) {
+	u := f.user
+	return &u, nil
+}
+
+func (f *fakeUserService) UpdateUser(context.Context, string, *port.UpdateUserReq) (*domain.User, error) {
+	u := f.user
+	return &u, nil
+}
+
+type fakeRBACService struct {
+	port.RBACService
+	roles []domain.Role
+}
+
+func (f *fakeRBACService) GetAllRoles() ([]domain.Role, error)        { return f.roles, nil }
+func (f *fakeRBACService) GetUserRoles(string) ([]domain.Role, error) { return f.roles, nil }
+func (f *fakeRBACService) GetDeletedRoles(context.Context) ([]domain.Role, error) {
+	return f.roles, nil
+}
+func (f *fakeRBACService) GetAllPermissions() ([]domain.Permission, error) {
+	return []domain.Permission{*f.roles[0].Permissions[0]}, nil
+}
+
+// Binding / Assignment Preload Role ที่มี User (และ Password Hash) ติดมาด้วย
+func (f *fakeRBACService) GetUserBindings(userID string) ([]domain.ResourceRoleBinding, error) {
+	role := f.roles[0]
+	return []domain.ResourceRoleBinding{{
+		Model:        domain.Model{Seq: 11, Uid: uuid.New()},
+		UserUid:      uuid.MustParse(userID),
+		RoleUid:      role.Uid,
+		ResourceType: "document",
+		ResourceID:   "42",
+		Role:         &role,
+	}}, nil
+}
+func (f *fakeRBACService) GetAllTenants() ([]domain.Tenant, error) {
+	return []domain.Tenant{{Model: domain.Model{Seq: 12, Uid: uuid.New()}, Name: "acme"}}, nil
+}
+func (f *fakeRBACService) GetUserTenantRoles(userID string) ([]domain.TenantRoleAssignment, error) {
+	role := f.roles[0]
+	tenant := domain.Tenant{Model: domain.Model{Seq: 12, Uid: uuid.New()}, Name: "acme"}
+	return []domain.TenantRoleAssignment{{
+		Model:     domain.Model{Seq: 13, Uid: uuid.New()},
+		TenantUid: tenant.Uid,
+		UserUid:   uuid.MustParse(userID),
+		RoleUid:   role.Uid,
+		Tenant:    &tenant,
+		Role:      &role,
+	}}, nil
+}
+func (f *fakeRBACService) GetUserDenies(userID string) ([]domain.UserDenyRule, error) {
+	return []domain.UserDenyRule{{Model: domain.Model{Seq: 14, Uid: uuid.New()}, UserUid: uuid.MustParse(userID), Permission: "billing:*"}}, nil
+}
+
+func TestResponsesNeverContainPasswordHash(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := domain.User{
+		Model:    domain.Model{Seq: 42, Uid: uuid.New(), CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		Username: "alice",
+		Email:    "alice@example.com",
+		Password: string(hash),
+	}
+	perm := &domain.Permission{Model: domain.Model{Uid: uuid.New()}, Name: "report:view", Description: "ดูรายงาน"}
+	role := domain.Role{
+		Model:       domain.Model{Seq: 7, Uid: uuid.New()},
+		Name:        "viewer",
+		Description: "อ่านอย่างเดียว",
+		Permissions: []*domain.Permission{perm},
+		Users:       []*domain.User{&user},
+	}
+	user.Roles = []*domain.Role{&role}
+
+	userHandler := NewUserHandler(&fakeUserService{user: user})
+	rbacHandler := NewRBACHandler(&fakeRBACService{roles: []domain.Role{role}})
+
+	app := fiber.New()
+	app.Get("/users", userHandler.ListUsers)
+	app.Get("/users/:id", userHandler.GetUser)
+	app.Patch("/users/:id", userHandler.UpdateUser)
+	app.Get("/roles", rbacHandler.GetRoles)
+	app.Get("/roles/deleted", rbacHandler.GetDeletedRoles)
+	app.Get("/permissions", rbacHandler.GetPermissions)
+	app.Get("/users/:id/roles", rbacHandler.GetUserRoles)
+	app.Get("/users/:id/bindings", rbacHandler.GetUserBindings)
+	app.Get("/users/:id/tenant-roles", rbacHandler.GetUserTenantRoles)
+	app.Get("/users/:id/denies", rbacHandler.GetUserDenies)
+	app.Get("/tenants", rbacHandler.GetTenants)
+
+	requests := []struct{ method, path, body string }{
+		{"GET", "/users", ""},
+		{"GET", "/users/" + user.Uid.String(), ""},
+		{"PATCH", "/users/" + user.Uid.String(), `{"email":"alice@example.com"}`},
+		{"GET", "/roles", ""},
+		{"GET", "/roles/deleted", ""},
+		{"GET", "/permissions", ""},
+		{"GET", "/users/" + user.Uid.String() + "/roles", ""},
+		{"GET", "/users/" + user.Uid.String() + "/bindings", ""},
+		{"GET", "/users/" + user.Uid.String() + "/tenant-roles", ""},
+		{"GET", "/users/" + user.Uid.String() + "/denies", ""},
+		{"GET", "/tenants", ""},
+	}
+
+	for _, r := range requests {
+		t.Run(r.method+" "+r.path, func(t *testing.T) {
+			req := httptest.NewRequest(r.method, r.path, strings.NewReader(r.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			res, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(res.Body)
+
+			if res.StatusCode != 200 {
+				t.Fatalf("status = %d, body = %s", res.StatusCode, body)
+			}
+			if !json.Valid(body) {
+				t.Fatalf("invalid JSON: %s", body)
+			}
+			for _, leak := range []string{string(hash), "$2a$", `"password"`, `"Seq"`} {
+				if strings.Contains(string(body), leak) {
+					t.Errorf("response contains %q: %s", leak, body)
+				}
+			}
+		})
+	}
+}
+
+// Domain ถูก Serialize ตรงๆ (เช่น Log หรือ Handler ที่ลืมแปลง) ก็ต้องไม่หลุด Hash
+func TestUserJSONOmitsPassword(t *testing.T) {
+	body, err := json.Marshal(domain.User{Username: "bob", Password: "$2a$10$abcdefghijklmnopqrstuv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), "$2a$") {
+		t.Errorf("domain.User JSON contains password hash: %s", body)
+	}
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"errors"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxAPIKeyRotateGrace Key เก่าใช้ต่อหลัง Rotate ได้ไม่เกินนี้ (กันตั้ง grace ยาวจน Rotate ไม่มีความหมาย)
+const maxAPIKeyRotateGrace = 7 * 24 * time.Hour
+
+type ServiceAccountHandler struct {
+	svc port.ServiceAccountService
+}
+
+func NewServiceAccountHandler(svc port.ServiceAccountService) *ServiceAccountHandler {
+	return &ServiceAccountHandler{svc: svc}
+}
+
+func (h *ServiceAccountHandler) ListServiceAccounts(c *fiber.Ctx) error {
+	accounts, err := h.svc.ListServiceAccounts(c.UserContext())
+	if err != nil {
+		return respondServiceAccountError(c, err)
+	}
+
+	res := make([]port.UserResponse, 0, len(accounts))
+	for i := range accounts {
+		res = append(res, toUserResponse(&accounts[i]))
+	}
+	return c.JSON(res)
+}
+
+func (h *ServiceAccountHandler) CreateServiceAccount(c *fiber.Ctx) error {
+	var req port.CreateServiceAccountReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+
+	account, err := h.svc.CreateServiceAccount(c.UserContext(), &req)
+	if err != nil {
+		return respondServiceAccountError(c, err)
+	}
+	return c.Status(201).JSON(toUserResponse(account))
+}
+
+func (h *ServiceAccountHandler) DeleteServiceAccount(c *fiber.Ctx) error {
+	if err := h.svc.DeleteServiceAccount(c.UserContext(), c.Params("id")); err != nil {
+		return respondServiceAccountError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Service account deleted"})
+}
+
+// ListAPIKeys แสดงแค่ Prefix (Key ตัวจริงเห็นได้ครั้งเดียวตอนสร้าง)
+func (h *ServiceAccountHandler) ListAPIKeys(c *fiber.Ctx) error {
+	keys, err := h.svc.ListAPIKeys(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return respondServiceAccountError(c, err)
+	}
+
+	res := make([]port.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		res = append(res, toAPIKeyResponse(&keys[i]))
+	}
+	return c.JSON(res)
+}
+
+func (h *ServiceAccountHandler) CreateAPIKey(c *fiber.Ctx) error {
+	var req port.CreateAPIKeyReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+
+	key, raw, err := h.svc.CreateAPIKey(c.UserContext(), c.Params("id"), &req)
+	if err != nil {
+		return respondServiceAccountError(c, err)
+	}
+	return c.Status(201).JSON(port.CreatedAPIKeyResponse{APIKeyResponse: toAPIKeyResponse(key), Key: raw})
+}
+
+// RotateAPIKey: POST .../keys/:keyId/rotate?grace=1h (ไม่ระบุ = Key เก่าใช้ไม่ได้ทันที, สูงสุด 7 วัน)
+func (h *ServiceAccountHandler) RotateAPIKey(c *fiber.Ctx) error {
+	var grace time.Duration
+	if v := c.Query("grace"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid grace duration"})
+		}
+		if d > maxAPIKeyRotateGrace {
+			return c.Status(400).JSON(fiber.Map{"error": "grace must not exceed " + maxAPIKeyRotateGrace.String()})
+		}
+		grace = d
+	}
+
+	key, raw, err := h.svc.RotateAPIKey(c.UserContext(), c.Params("id"), c.Params("keyId"), grace)
+	if err != nil {
+		return respondServiceAccountError(c, err)
+	}
+	return c.Status(201).JSON(port.CreatedAPIKeyResponse{APIKeyResponse: toAPIKeyResponse(key), Key: raw})
+}
+
+func (h *ServiceAccountHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	if err := h.svc.RevokeAPIKey(c.UserContext(), c.Params("id"), c.Params("keyId")); err != nil {
+		return respondServiceAccountError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "API key revoked"})
+}
+
+// respondServiceAccountError: id ไม่ใช่ Service Account = 400, ไม่พบ = 404, ชื่อซ้ำ = 409, ข้อมูลผิด = 422
+func respondServiceAccountError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		return respondValidationError(c, err)
+	case errors.Is(err, domain.ErrNotServiceAccount):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrAlreadyExists):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
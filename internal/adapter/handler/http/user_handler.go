@@ -0,0 +1,115 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/gofiber/fiber/v2"
+)
+
+type UserHandler struct {
+	svc port.UserService
+}
+
+func NewUserHandler(svc port.UserService) *UserHandler {
+	return &UserHandler{svc: svc}
+}
+
+// ListUsers: GET /users?q=&status=active|disabled|deleted&kind=user|service&page=&page_size=
+func (h *UserHandler) ListUsers(c *fiber.Ctx) error {
+	query := port.UserListQuery{
+		Search:   c.Query("q"),
+		Status:   c.Query("status"),
+		Kind:     c.Query("kind"),
+		Page:     c.QueryInt("page", 1),
+		PageSize: c.QueryInt("page_size", 20),
+	}
+
+	users, total, err := h.svc.ListUsers(c.UserContext(), &query)
+	if err != nil {
+		return respondUserError(c, err)
+	}
+
+	page := port.UserPage{
+		Users:    make([]port.UserResponse, 0, len(users)),
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		Total:    total,
+	}
+	for i := range users {
+		page.Users = append(page.Users, toUserResponse(&users[i]))
+	}
+	return c.JSON(page)
+}
+
+func (h *UserHandler) GetUser(c *fiber.Ctx) error {
+	user, err := h.svc.GetUser(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return respondUserError(c, err)
+	}
+	return c.JSON(toUserResponse(user))
+}
+
+func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
+	var req port.UpdateUserReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "bad request"})
+	}
+
+	user, err := h.svc.UpdateUser(c.UserContext(), c.Params("id"), &req)
+	if err != nil {
+		return respondUserError(c, err)
+	}
+	return c.JSON(toUserResponse(user))
+}
+
+func (h *UserHandler) DisableUser(c *fiber.Ctx) error {
+	if err := h.svc.DisableUser(c.UserContext(), c.Params("id")); err != nil {
+		return respondUserError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "User disabled"})
+}
+
+func (h *UserHandler) EnableUser(c *fiber.Ctx) error {
+	if err := h.svc.EnableUser(c.UserContext(), c.Params("id")); err != nil {
+		return respondUserError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "User enabled"})
+}
+
+func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
+	if err := h.svc.DeleteUser(c.UserContext(), c.Params("id")); err != nil {
+		return respondUserError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "User deleted"})
+}
+
+func (h *UserHandler) RestoreUser(c *fiber.Ctx) error {
+	if err := h.svc.RestoreUser(c.UserContext(), c.Params("id")); err != nil {
+		return respondUserError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "User restored"})
+}
+
+func (h *UserHandler) ForcePasswordReset(c *fiber.Ctx) error {
+	if err := h.svc.ForcePasswordReset(c.UserContext(), c.Params("id")); err != nil {
+		return respondUserError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Password reset required on next login"})
+}
+
+// respondUserError: ไม่พบ = 404, Username / Email ซ้ำ = 409, Filter ผิด = 400, ข้อมูลผิด = 422
+func respondUserError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		return respondValidationError(c, err)
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrAlreadyExists):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidQuery):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,195 @@
+package keystore
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+type key struct {
+	id     string
+	alg    string
+	signer crypto.Signer    // nil ถ้าเป็นกุญแจเก่าที่เหลือไว้แค่ตรวจ Token
+	public crypto.PublicKey // ใช้ตรวจ Token และออก JWKS
+}
+
+type keySet struct {
+	active *key
+	keys   map[string]*key
+}
+
+// NewKeySet โหลดกุญแจจากไฟล์ PEM ตาม config
+// ถ้าไม่ได้ตั้งกุญแจไว้เลย จะสร้างกุญแจ Ed25519 ชั่วคราวให้ (ใช้ตอน Dev เท่านั้น)
+func NewKeySet(cfg config.AuthConfig) (port.KeySet, error) {
+	ks := &keySet{keys: make(map[string]*key)}
+
+	if len(cfg.SigningKeys) == 0 {
+		return newEphemeralKeySet()
+	}
+
+	for _, kc := range cfg.SigningKeys {
+		k, err := loadKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %q: %w", kc.KID, err)
+		}
+		if _, dup := ks.keys[k.id]; dup {
+			return nil, fmt.Errorf("duplicate signing key id: %s", k.id)
+		}
+		ks.keys[k.id] = k
+	}
+
+	active, ok := ks.keys[cfg.ActiveKeyID]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %q not found", cfg.ActiveKeyID)
+	}
+	if active.signer == nil {
+		return nil, fmt.Errorf("active signing key %q has no private key", cfg.ActiveKeyID)
+	}
+	ks.active = active
+
+	return ks, nil
+}
+
+func (ks *keySet) SigningKey() (string, string, crypto.Signer) {
+	return ks.active.id, ks.active.alg, ks.active.signer
+}
+
+func (ks *keySet) VerificationKey(kid string) (string, crypto.PublicKey, bool) {
+	k, ok := ks.keys[kid]
+	if !ok {
+		return "", nil, false
+	}
+	return k.alg, k.public, true
+}
+
+func (ks *keySet) JWKS() *port.JWKSet {
+	set := &port.JWKSet{Keys: make([]port.JWK, 0, len(ks.keys))}
+	for _, k := range ks.keys {
+		jwk := port.JWK{Kid: k.id, Use: "sig", Alg: k.alg}
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	// เรียงตาม kid ให้ Response คงที่ทุกครั้ง (Cache ได้)
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+func newEphemeralKeySet() (port.KeySet, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	k := &key{id: "ephemeral", alg: AlgEdDSA, signer: priv, public: pub}
+	log.Printf("⚠️ No signing keys configured, using an ephemeral Ed25519 key (tokens die on restart, DEV ONLY)")
+
+	return &keySet{active: k, keys: map[string]*key{k.id: k}}, nil
+}
+
+// loadKey อ่าน Private Key (ใช้เซ็น+ตรวจ) หรือ Public Key (ตรวจอย่างเดียว สำหรับกุญแจที่เลิกใช้แล้ว)
+func loadKey(kc config.SigningKeyConfig) (*key, error) {
+	if kc.KID == "" {
+		return nil, fmt.Errorf("kid is required")
+	}
+	if kc.Algorithm != AlgRS256 && kc.Algorithm != AlgEdDSA {
+		return nil, fmt.Errorf("unsupported algorithm: %s", kc.Algorithm)
+	}
+
+	k := &key{id: kc.KID, alg: kc.Algorithm}
+
+	if kc.PrivateKeyFile != "" {
+		block, err := readPEM(kc.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		priv, err := parsePrivateKey(block)
+		if err != nil {
+			return nil, err
+		}
+		k.signer = priv
+		k.public = priv.Public()
+	} else if kc.PublicKeyFile != "" {
+		block, err := readPEM(kc.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		k.public = pub
+	} else {
+		return nil, fmt.Errorf("private_key_file or public_key_file is required")
+	}
+
+	// กุญแจต้องตรงกับ Algorithm ที่ประกาศไว้ กันตั้ง config ผิด
+	switch k.public.(type) {
+	case *rsa.PublicKey:
+		if k.alg != AlgRS256 {
+			return nil, fmt.Errorf("RSA key cannot be used with %s", k.alg)
+		}
+	case ed25519.PublicKey:
+		if k.alg != AlgEdDSA {
+			return nil, fmt.Errorf("Ed25519 key cannot be used with %s", k.alg)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", k.public)
+	}
+
+	return k, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+	return block, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	// PKCS#1 (BEGIN RSA PRIVATE KEY)
+	if rsaKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return rsaKey, nil
+	}
+
+	// PKCS#8 (BEGIN PRIVATE KEY) ใช้ได้ทั้ง RSA และ Ed25519
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+	return signer, nil
+}
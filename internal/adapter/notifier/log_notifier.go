@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+// logNotifier ใช้ตอน Dev: ไม่ส่งจริง แค่เขียนข้อความลง Log หรือไฟล์ (ข้อความมี Token อยู่ ห้ามใช้ใน Production)
+type logNotifier struct {
+	from string
+	mu   sync.Mutex
+	file *os.File // nil = ใช้ log.Printf
+}
+
+func NewLogNotifier(from string, path string) (port.Notifier, error) {
+	n := &logNotifier{from: from}
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("open notifier log file: %w", err)
+		}
+		n.file = f
+	}
+	return n, nil
+}
+
+func (n *logNotifier) Send(ctx context.Context, msg *port.Notification) error {
+	if n.file == nil {
+		log.Printf("📧 [notifier] To: %s | Subject: %s\n%s", msg.To, msg.Subject, msg.Body)
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, err := fmt.Fprintf(n.file, "Date: %s\nFrom: %s\nTo: %s\nSubject: %s\n\n%s\n\n----\n",
+		time.Now().Format(time.RFC1123Z), n.from, msg.To, msg.Subject, msg.Body)
+	return err
+}
@@ -0,0 +1,26 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+// NewNotifier เลือก Adapter ตาม cfg.Driver ("log" หรือ "smtp")
+func NewNotifier(cfg config.NotifierConfig) (port.Notifier, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return NewLogNotifier(cfg.From, cfg.LogFile)
+	case "smtp":
+		return NewSMTPNotifier(cfg.From, cfg.SMTP)
+	default:
+		return nil, fmt.Errorf("unknown notifier driver %q", cfg.Driver)
+	}
+}
+
+// validHeader กัน Header Injection (To / Subject ห้ามมีขึ้นบรรทัดใหม่)
+func validHeader(v string) bool {
+	return !strings.ContainsAny(v, "\r\n")
+}
@@ -0,0 +1,105 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+type smtpNotifier struct {
+	from string
+	host string
+	addr string
+	auth smtp.Auth // nil = ไม่ AUTH
+}
+
+func NewSMTPNotifier(from string, cfg config.SMTPConfig) (port.Notifier, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("notifier.smtp.host is required")
+	}
+	if from == "" || !validHeader(from) {
+		return nil, errors.New("notifier.from must be a valid address")
+	}
+
+	n := &smtpNotifier{from: from, host: cfg.Host, addr: net.JoinHostPort(cfg.Host, cfg.Port)}
+	if cfg.Username != "" {
+		// PlainAuth ยอมส่งรหัสผ่านเฉพาะตอนเป็น TLS (หรือ localhost) เท่านั้น
+		n.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return n, nil
+}
+
+// Send ต่อ SMTP ใหม่ทุกครั้ง (ปริมาณอีเมลน้อย) และเคารพ Deadline ของ ctx
+func (n *smtpNotifier) Send(ctx context.Context, msg *port.Notification) error {
+	if !validHeader(msg.To) || !validHeader(msg.Subject) {
+		return errors.New("notification header contains a line break")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return err
+		}
+	}
+	if n.auth != nil {
+		if err := client.Auth(n.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(n.buildMessage(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (n *smtpNotifier) buildMessage(msg *port.Notification) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", n.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.Write(bytes.ReplaceAll([]byte(msg.Body), []byte("\n"), []byte("\r\n")))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
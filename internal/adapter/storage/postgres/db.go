@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -22,13 +23,29 @@ func NewPostgresDatabase(cfg *config.Config) (*gorm.DB, error) {
 	)
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info), // ให้ปริ้น SQL ออกมาดูตอน Dev
+		Logger:         logger.Default.LogMode(logger.Info), // ให้ปริ้น SQL ออกมาดูตอน Dev
+		TranslateError: true,                                // ให้ได้ gorm.ErrDuplicatedKey แทน Error ของ Driver
 	})
 
 	if err != nil {
 		return nil, fmt.Errorf("cannot connect to database: %w", err)
 	}
 
+	// ใช้ Model ของตาราง Join เองแทนที่ GORM สร้างให้ (user_roles มีช่วงเวลาใช้งาน)
+	if err := db.SetupJoinTable(&domain.User{}, "Roles", &domain.UserRole{}); err != nil {
+		return nil, fmt.Errorf("cannot setup user_roles join table: %w", err)
+	}
+	if err := db.SetupJoinTable(&domain.Role{}, "Users", &domain.UserRole{}); err != nil {
+		return nil, fmt.Errorf("cannot setup user_roles join table: %w", err)
+	}
+	// role_permissions มี Condition ของแต่ละคู่
+	if err := db.SetupJoinTable(&domain.Role{}, "Permissions", &domain.RolePermission{}); err != nil {
+		return nil, fmt.Errorf("cannot setup role_permissions join table: %w", err)
+	}
+	if err := db.SetupJoinTable(&domain.Permission{}, "Roles", &domain.RolePermission{}); err != nil {
+		return nil, fmt.Errorf("cannot setup role_permissions join table: %w", err)
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, fmt.Errorf("cannot get database instance: %w", err)
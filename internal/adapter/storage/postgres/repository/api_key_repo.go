@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"gorm.io/gorm"
+)
+
+type apiKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) port.APIKeyRepository {
+	return &apiKeyRepo{db: db}
+}
+
+func (r *apiKeyRepo) Create(ctx context.Context, key *domain.APIKey) error {
+	return translateError(dbFrom(ctx, r.db).Create(key).Error)
+}
+
+func (r *apiKeyRepo) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	if err := dbFrom(ctx, r.db).Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepo) GetByUID(ctx context.Context, uid string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	if err := dbFrom(ctx, r.db).Where("uid = ?", uid).First(&key).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepo) ListByUser(ctx context.Context, userID string) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	err := dbFrom(ctx, r.db).Where("user_uid = ?", userID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepo) Revoke(ctx context.Context, uid string) error {
+	res := dbFrom(ctx, r.db).Model(&domain.APIKey{}).
+		Where("uid = ? AND revoked_at IS NULL", uid).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *apiKeyRepo) RevokeAllForUser(ctx context.Context, userID string) error {
+	return dbFrom(ctx, r.db).Model(&domain.APIKey{}).
+		Where("user_uid = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *apiKeyRepo) SetExpiry(ctx context.Context, uid string, at time.Time) error {
+	return dbFrom(ctx, r.db).Model(&domain.APIKey{}).
+		Where("uid = ?", uid).
+		Update("expires_at", at).Error
+}
+
+// TouchLastUsed ไม่แตะ updated_at (เป็นแค่สถิติ)
+func (r *apiKeyRepo) TouchLastUsed(ctx context.Context, uid string, at time.Time) error {
+	return dbFrom(ctx, r.db).Model(&domain.APIKey{}).
+		Where("uid = ?", uid).
+		UpdateColumn("last_used_at", at).Error
+}
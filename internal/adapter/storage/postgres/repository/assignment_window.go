@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// activeWindowClause เงื่อนไข SQL ของ Assignment ที่ใช้งานได้ ณ เวลา now (ใช้คู่กับ args now, now)
+func activeWindowClause(table string) string {
+	return fmt.Sprintf("(%[1]s.valid_from IS NULL OR %[1]s.valid_from <= ?) AND (%[1]s.valid_until IS NULL OR %[1]s.valid_until > ?)", table)
+}
+
+// nextWindowChange หาเวลาที่ใกล้ที่สุดหลัง now ที่ Assignment ของ User จะเริ่มหรือหมดอายุ
+// (ใช้กำหนด TTL ของ Cache ไม่ให้เกินเวลานั้น) คืน nil ถ้าไม่มี
+func nextWindowChange(ctx context.Context, db *gorm.DB, table string, where string, args []interface{}, now time.Time) (*time.Time, error) {
+	query := fmt.Sprintf(`SELECT MIN(t) FROM (
+		SELECT valid_until AS t FROM %[1]s WHERE %[2]s AND valid_until > ?
+		UNION ALL
+		SELECT valid_from AS t FROM %[1]s WHERE %[2]s AND valid_from > ?
+	) AS changes`, table, where)
+
+	params := make([]interface{}, 0, 2*len(args)+2)
+	params = append(params, args...)
+	params = append(params, now)
+	params = append(params, args...)
+	params = append(params, now)
+
+	var next sql.NullTime
+	if err := dbFrom(ctx, db).Raw(query, params...).Scan(&next).Error; err != nil {
+		return nil, err
+	}
+	if !next.Valid {
+		return nil, nil
+	}
+	return &next.Time, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"gorm.io/gorm"
+)
+
+// auditChainLockID Key ของ pg_advisory_xact_lock ที่ใช้ต่อท้าย Audit Chain (ค่าคงที่ ใช้ร่วมกันทุก Instance)
+const auditChainLockID = 0x61756469 // "audi"
+
+// likeEscaper ใช้กับ Prefix ที่มาจาก Query ของ User
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type auditRepo struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) port.AuditRepository {
+	return &auditRepo{db: db}
+}
+
+// Append ต้องอ่านรายการสุดท้ายกับเขียนรายการใหม่ใน Lock เดียวกัน ไม่งั้น 2 Request จะชี้ PrevHash ไปที่เดียวกัน
+func (r *auditRepo) Append(ctx context.Context, event *domain.AuditEvent) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockID).Error; err != nil {
+			return err
+		}
+
+		var last domain.AuditEvent
+		if err := tx.Order("seq DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+
+		event.Seq = last.Seq + 1
+		event.PrevHash = last.Hash
+		event.Hash = event.ComputeHash()
+		return tx.Create(event).Error
+	})
+}
+
+func (r *auditRepo) List(ctx context.Context, filter port.AuditFilter) ([]domain.AuditEvent, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&domain.AuditEvent{})
+	if filter.ActorID != "" {
+		q = q.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		q = q.Where("action = ?", filter.Action)
+	}
+	if filter.ActionPrefix != "" {
+		q = q.Where(`action LIKE ? ESCAPE '\'`, likeEscaper.Replace(filter.ActionPrefix)+"%")
+	}
+	if filter.TargetType != "" {
+		q = q.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		q = q.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Outcome != "" {
+		q = q.Where("outcome = ?", filter.Outcome)
+	}
+	if filter.RequestID != "" {
+		q = q.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		q = q.Where("occurred_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q = q.Where("occurred_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []domain.AuditEvent
+	err := q.Order("seq DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+func (r *auditRepo) ListAfter(ctx context.Context, afterSeq int64, limit int) ([]domain.AuditEvent, error) {
+	var events []domain.AuditEvent
+	err := dbFrom(ctx, r.db).
+		Where("seq > ?", afterSeq).
+		Order("seq").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"gorm.io/gorm"
+)
+
+type denyRuleRepo struct {
+	db *gorm.DB
+}
+
+func NewDenyRuleRepository(db *gorm.DB) port.DenyRuleRepository {
+	return &denyRuleRepo{db: db}
+}
+
+func (r *denyRuleRepo) AddRoleDeny(ctx context.Context, rule *domain.RoleDenyRule) error {
+	return dbFrom(ctx, r.db).Create(rule).Error
+}
+
+func (r *denyRuleRepo) RemoveRoleDeny(ctx context.Context, roleID string, permission string) error {
+	// ลบจริง (Unscoped) เพราะมี Unique Index
+	res := dbFrom(ctx, r.db).Unscoped().
+		Where("role_uid = ? AND permission = ?", roleID, permission).
+		Delete(&domain.RoleDenyRule{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("role has no deny rule for: %s", permission)
+	}
+	return nil
+}
+
+func (r *denyRuleRepo) AddUserDeny(ctx context.Context, rule *domain.UserDenyRule) error {
+	return dbFrom(ctx, r.db).Create(rule).Error
+}
+
+func (r *denyRuleRepo) RemoveUserDeny(ctx context.Context, userID string, permission string) error {
+	res := dbFrom(ctx, r.db).Unscoped().
+		Where("user_uid = ? AND permission = ?", userID, permission).
+		Delete(&domain.UserDenyRule{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("user has no deny rule for: %s", permission)
+	}
+	return nil
+}
+
+func (r *denyRuleRepo) GetUserDenies(ctx context.Context, userID string) ([]domain.UserDenyRule, error) {
+	var rules []domain.UserDenyRule
+	err := dbFrom(ctx, r.db).Where("user_uid = ?", userID).Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *denyRuleRepo) GetAllUserDenies(ctx context.Context) ([]domain.UserDenyRule, error) {
+	var rules []domain.UserDenyRule
+	err := dbFrom(ctx, r.db).Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
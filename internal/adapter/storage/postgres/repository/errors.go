@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"gorm.io/gorm"
+)
+
+// translateError แปลง Error ของ GORM เป็น Error ของ Domain ให้ Handler แยก 404 / 409 ได้
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return domain.ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return domain.ErrAlreadyExists
+	}
+	return err
+}
+
+// softDelete / restore ใช้ร่วมกันระหว่าง Role และ Permission
+// cascadeSQL คือคำสั่งลบความสัมพันธ์ (อ้างถึง uid ด้วย @uid)
+func softDelete(tx *gorm.DB, model interface{}, uid string, cascadeSQL []string) error {
+	for _, stmt := range cascadeSQL {
+		if err := tx.Exec(stmt, sql.Named("uid", uid)).Error; err != nil {
+			return err
+		}
+	}
+
+	res := tx.Where("uid = ?", uid).Delete(model)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func restore(db *gorm.DB, model interface{}, uid string) error {
+	res := db.Unscoped().Model(model).Where("uid = ? AND deleted_at IS NOT NULL", uid).Update("deleted_at", nil)
+	if res.Error != nil {
+		return translateError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
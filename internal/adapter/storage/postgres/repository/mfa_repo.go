@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mfaRepo struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) port.MFARepository {
+	return &mfaRepo{db: db}
+}
+
+func (r *mfaRepo) GetCredential(ctx context.Context, userID string) (*domain.MFACredential, error) {
+	var cred domain.MFACredential
+	if err := dbFrom(ctx, r.db).Where("user_uid = ?", userID).First(&cred).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &cred, nil
+}
+
+func (r *mfaRepo) SaveCredential(ctx context.Context, cred *domain.MFACredential) error {
+	return dbFrom(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_uid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "confirmed_at", "last_used_step", "updated_at"}),
+	}).Create(cred).Error
+}
+
+func (r *mfaRepo) ConfirmCredential(ctx context.Context, userID string) error {
+	res := dbFrom(ctx, r.db).Model(&domain.MFACredential{}).
+		Where("user_uid = ? AND confirmed_at IS NULL", userID).
+		Update("confirmed_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *mfaRepo) DeleteCredential(ctx context.Context, userID string) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_uid = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_uid = ?", userID).Delete(&domain.MFACredential{}).Error
+	})
+}
+
+// MarkStepUsed อัปเดตแบบมีเงื่อนไข กัน 2 Request ใช้ Code เดียวกันพร้อมกัน
+func (r *mfaRepo) MarkStepUsed(ctx context.Context, userID string, step int64) (bool, error) {
+	res := dbFrom(ctx, r.db).Model(&domain.MFACredential{}).
+		Where("user_uid = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *mfaRepo) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return domain.ErrNotFound
+	}
+
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_uid = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]domain.RecoveryCode, 0, len(codeHashes))
+		for _, h := range codeHashes {
+			codes = append(codes, domain.RecoveryCode{UserUid: uid, CodeHash: h})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *mfaRepo) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	res := dbFrom(ctx, r.db).Model(&domain.RecoveryCode{}).
+		Where("user_uid = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
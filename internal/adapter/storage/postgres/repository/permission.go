@@ -17,12 +17,12 @@ func NewPermissionRepository(db *gorm.DB) port.PermissionRepository {
 }
 
 func (r *permissionRepo) Create(ctx context.Context, perm *domain.Permission) error {
-	return r.db.WithContext(ctx).Create(perm).Error
+	return translateError(dbFrom(ctx, r.db).Create(perm).Error)
 }
 
 func (r *permissionRepo) GetAll(ctx context.Context) ([]domain.Permission, error) {
 	var perms []domain.Permission
-	err := r.db.WithContext(ctx).Find(&perms).Error
+	err := dbFrom(ctx, r.db).Find(&perms).Error
 	if err != nil {
 		return nil, err
 	}
@@ -31,9 +31,43 @@ func (r *permissionRepo) GetAll(ctx context.Context) ([]domain.Permission, error
 
 func (r *permissionRepo) GetPermissionByName(ctx context.Context, name string) (*domain.Permission, error) {
 	var perm domain.Permission
-	err := r.db.WithContext(ctx).Where("name = ?", name).First(&perm).Error
+	err := dbFrom(ctx, r.db).Where("name = ?", name).First(&perm).Error
 	if err != nil {
 		return nil, err
 	}
 	return &perm, nil
 }
+
+func (r *permissionRepo) GetPermissionByUID(ctx context.Context, uid string) (*domain.Permission, error) {
+	var perm domain.Permission
+	if err := dbFrom(ctx, r.db).Where("uid = ?", uid).First(&perm).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &perm, nil
+}
+
+func (r *permissionRepo) Update(ctx context.Context, perm *domain.Permission) error {
+	return translateError(dbFrom(ctx, r.db).Model(perm).Select("name", "description").Updates(perm).Error)
+}
+
+func (r *permissionRepo) Delete(ctx context.Context, uid string, cascade bool) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var cascadeSQL []string
+		if cascade {
+			cascadeSQL = []string{"DELETE FROM role_permissions WHERE permission_uid = @uid"}
+		}
+		return softDelete(tx, &domain.Permission{}, uid, cascadeSQL)
+	})
+}
+
+func (r *permissionRepo) Restore(ctx context.Context, uid string) error {
+	return restore(dbFrom(ctx, r.db), &domain.Permission{}, uid)
+}
+
+func (r *permissionRepo) GetDeleted(ctx context.Context) ([]domain.Permission, error) {
+	var perms []domain.Permission
+	if err := dbFrom(ctx, r.db).Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&perms).Error; err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"gorm.io/gorm"
+)
+
+type refreshTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) port.RefreshTokenRepository {
+	return &refreshTokenRepo{db: db}
+}
+
+func (r *refreshTokenRepo) Create(ctx context.Context, token *domain.RefreshToken) error {
+	return dbFrom(ctx, r.db).Create(token).Error
+}
+
+func (r *refreshTokenRepo) GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := dbFrom(ctx, r.db).Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *refreshTokenRepo) Rotate(ctx context.Context, old *domain.RefreshToken, next *domain.RefreshToken) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+
+		// อัปเดตแบบมีเงื่อนไข revoked_at IS NULL กัน Request ซ้อนกันใช้ Token เดียวกันได้ 2 ครั้ง
+		res := tx.Model(&domain.RefreshToken{}).
+			Where("uid = ? AND revoked_at IS NULL", old.Uid).
+			Updates(map[string]interface{}{
+				"revoked_at":  time.Now(),
+				"replaced_by": next.Uid,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrRefreshTokenReused
+		}
+		return nil
+	})
+}
+
+func (r *refreshTokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	return dbFrom(ctx, r.db).
+		Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *refreshTokenRepo) RevokeAllForUser(ctx context.Context, userID string) error {
+	return dbFrom(ctx, r.db).
+		Model(&domain.RefreshToken{}).
+		Where("user_uid = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"gorm.io/gorm"
+)
+
+type resourceBindingRepo struct {
+	db *gorm.DB
+}
+
+func NewResourceBindingRepository(db *gorm.DB) port.ResourceBindingRepository {
+	return &resourceBindingRepo{db: db}
+}
+
+func (r *resourceBindingRepo) Create(ctx context.Context, binding *domain.ResourceRoleBinding) error {
+	return dbFrom(ctx, r.db).Create(binding).Error
+}
+
+func (r *resourceBindingRepo) Delete(ctx context.Context, userID string, roleID string, resourceType string, resourceID string) error {
+	// ลบจริง (Unscoped) เพราะมี Unique Index ถ้า Soft Delete จะผูกซ้ำไม่ได้
+	res := dbFrom(ctx, r.db).Unscoped().
+		Where("user_uid = ? AND role_uid = ? AND resource_type = ? AND resource_id = ?", userID, roleID, resourceType, resourceID).
+		Delete(&domain.ResourceRoleBinding{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("binding not found: %s/%s", resourceType, resourceID)
+	}
+	return nil
+}
+
+func (r *resourceBindingRepo) GetByUserUID(ctx context.Context, userID string) ([]domain.ResourceRoleBinding, error) {
+	var bindings []domain.ResourceRoleBinding
+	err := dbFrom(ctx, r.db).
+		Preload("Role").
+		Where("user_uid = ?", userID).
+		Find(&bindings).Error
+	if err != nil {
+		return nil, err
+	}
+	return bindings, nil
+}
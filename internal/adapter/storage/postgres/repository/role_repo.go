@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type roleRepo struct {
@@ -18,12 +21,12 @@ func NewRoleRepository(db *gorm.DB) port.RoleRepository {
 }
 
 func (r *roleRepo) Create(ctx context.Context, role *domain.Role) error {
-	return r.db.WithContext(ctx).Create(role).Error
+	return translateError(dbFrom(ctx, r.db).Create(role).Error)
 }
 
 func (r *roleRepo) GetAll(ctx context.Context) ([]domain.Role, error) {
 	var roles []domain.Role
-	err := r.db.WithContext(ctx).Preload("Permissions").Find(&roles).Error
+	err := dbFrom(ctx, r.db).Preload("Permissions").Preload("PermissionLinks").Preload("Parents").Preload("Denies").Find(&roles).Error
 	if err != nil {
 		return nil, err
 	}
@@ -33,9 +36,10 @@ func (r *roleRepo) GetAll(ctx context.Context) ([]domain.Role, error) {
 
 func (r *roleRepo) GetRoleByUserUID(ctx context.Context, userUid string) ([]domain.Role, error) {
 	var roles []domain.Role
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Joins("JOIN user_roles ON user_roles.role_uid = roles.uid"). // ชื่อตารางและคอลัมน์ต้องตรงกับใน DB จริง
 		Where("user_roles.user_uid = ?", userUid).
+		Where(activeWindowClause("user_roles"), time.Now(), time.Now()). // ข้าม Assignment ที่ยังไม่เริ่ม/หมดอายุ
 		Find(&roles).Error
 
 	if err != nil {
@@ -44,42 +48,192 @@ func (r *roleRepo) GetRoleByUserUID(ctx context.Context, userUid string) ([]doma
 	return roles, nil
 }
 
+func (r *roleRepo) GetNextRoleChange(ctx context.Context, userUid string, now time.Time) (*time.Time, error) {
+	return nextWindowChange(ctx, r.db, "user_roles", "user_uid = ?", []interface{}{userUid}, now)
+}
+
 func (r *roleRepo) GetRoleByName(ctx context.Context, name string) (*domain.Role, error) {
 	var role domain.Role
-	err := r.db.WithContext(ctx).Where("name = ?", name).First(&role).Error
+	err := dbFrom(ctx, r.db).Where("name = ?", name).First(&role).Error
 	if err != nil {
 		return nil, err
 	}
 	return &role, nil
 }
 
-func (r *roleRepo) AddAccosiatePermission(ctx context.Context, roleID string, permID string) error {
+func (r *roleRepo) AddAccosiatePermission(ctx context.Context, roleID string, permID string, condition string) error {
 	// หา Role และ Permission
 	var role domain.Role
-	if err := r.db.WithContext(ctx).Where("uid = ?", roleID).First(&role).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Where("uid = ?", roleID).First(&role).Error; err != nil {
 		return err
 	}
 	var perm domain.Permission
-	if err := r.db.WithContext(ctx).Where("uid = ?", permID).First(&perm).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Where("uid = ?", permID).First(&perm).Error; err != nil {
 		return err
 	}
-	// จับคู่ Role <-> Permission
-	return r.db.WithContext(ctx).Model(&role).Association("Permissions").Append(&perm)
+	// จับคู่ Role <-> Permission (ถ้ามีอยู่แล้วให้อัปเดตเงื่อนไข)
+	link := domain.RolePermission{
+		RoleUid:       role.Uid,
+		PermissionUid: perm.Uid,
+		Condition:     condition,
+	}
+	return dbFrom(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "role_uid"}, {Name: "permission_uid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"condition"}),
+	}).Create(&link).Error
 }
 
 func (r *roleRepo) RemoveAssociatePermission(ctx context.Context, roleID string, permID string) error {
 	var role domain.Role
-	if err := r.db.WithContext(ctx).Where("uid = ?", roleID).First(&role).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Where("uid = ?", roleID).First(&role).Error; err != nil {
 		return err
 	}
 	var perm domain.Permission
-	if err := r.db.WithContext(ctx).Where("uid = ?", permID).First(&perm).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Where("uid = ?", permID).First(&perm).Error; err != nil {
 		return err
 	}
-	count := r.db.WithContext(ctx).Model(&role).Where("uid = ?", permID).Association("Permissions").Count()
+	count := dbFrom(ctx, r.db).Model(&role).Where("uid = ?", permID).Association("Permissions").Count()
 	if count == 0 {
 		return fmt.Errorf("role does not have permission: %s", perm.Name)
 	}
 	// ลบความสัมพันธ์ในตาราง role_permissions
-	return r.db.WithContext(ctx).Model(&role).Association("Permissions").Delete(&perm)
+	return dbFrom(ctx, r.db).Model(&role).Association("Permissions").Delete(&perm)
+}
+
+func (r *roleRepo) AddParent(ctx context.Context, roleID string, parentID string) error {
+	var role domain.Role
+	if err := dbFrom(ctx, r.db).Where("uid = ?", roleID).First(&role).Error; err != nil {
+		return err
+	}
+	var parent domain.Role
+	if err := dbFrom(ctx, r.db).Where("uid = ?", parentID).First(&parent).Error; err != nil {
+		return err
+	}
+	// จับคู่ Role -> Parent Role (ตาราง role_parents)
+	return dbFrom(ctx, r.db).Model(&role).Association("Parents").Append(&parent)
+}
+
+func (r *roleRepo) RemoveParent(ctx context.Context, roleID string, parentID string) error {
+	var role domain.Role
+	if err := dbFrom(ctx, r.db).Where("uid = ?", roleID).First(&role).Error; err != nil {
+		return err
+	}
+	var parent domain.Role
+	if err := dbFrom(ctx, r.db).Where("uid = ?", parentID).First(&parent).Error; err != nil {
+		return err
+	}
+	count := dbFrom(ctx, r.db).Model(&role).Where("uid = ?", parentID).Association("Parents").Count()
+	if count == 0 {
+		return fmt.Errorf("role %s does not inherit from: %s", role.Name, parent.Name)
+	}
+	// ลบความสัมพันธ์ในตาราง role_parents
+	return dbFrom(ctx, r.db).Model(&role).Association("Parents").Delete(&parent)
+}
+
+// GetRoleHolders รวม Assignment ที่ใช้งานได้ตอนนี้จาก 3 ตาราง (ข้าม User / Role ที่ถูกลบ)
+// Role ระดับ Platform ใช้ได้ทุก Tenant จึงไม่ถูกตัดออกเมื่อกรองด้วย TenantID
+func (r *roleRepo) GetRoleHolders(ctx context.Context, roleNames []string, filter port.RoleHolderFilter) ([]port.RoleHolderRow, error) {
+	now := time.Now()
+	userFilter := ""
+	var userArgs []interface{}
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		userFilter = " AND (u.username ILIKE ? OR u.email ILIKE ?)"
+		userArgs = []interface{}{pattern, pattern}
+	}
+	if filter.ActiveOnly {
+		userFilter += " AND u.disabled_at IS NULL"
+	}
+
+	var parts []string
+	var args []interface{}
+
+	if filter.Scope == "" || filter.Scope == port.RoleOriginPlatform {
+		parts = append(parts, `SELECT u.uid AS user_uid, u.username, u.email, r.name AS role_name, 'platform' AS scope,
+			NULL AS tenant_uid, '' AS resource_type, '' AS resource_id
+		FROM user_roles JOIN users u ON u.uid = user_roles.user_uid JOIN roles r ON r.uid = user_roles.role_uid
+		WHERE u.deleted_at IS NULL AND r.deleted_at IS NULL AND r.name IN ? AND `+activeWindowClause("user_roles")+userFilter)
+		args = append(args, roleNames, now, now)
+		args = append(args, userArgs...)
+	}
+
+	if filter.Scope == "" || filter.Scope == port.RoleOriginTenant {
+		tenantFilter := ""
+		tenantArgs := []interface{}{}
+		if filter.TenantID != "" {
+			tenantFilter = " AND tenant_user_roles.tenant_uid = ?"
+			tenantArgs = append(tenantArgs, filter.TenantID)
+		}
+		parts = append(parts, `SELECT u.uid AS user_uid, u.username, u.email, r.name AS role_name, 'tenant' AS scope,
+			CAST(tenant_user_roles.tenant_uid AS TEXT) AS tenant_uid, '' AS resource_type, '' AS resource_id
+		FROM tenant_user_roles JOIN users u ON u.uid = tenant_user_roles.user_uid JOIN roles r ON r.uid = tenant_user_roles.role_uid
+		WHERE u.deleted_at IS NULL AND r.deleted_at IS NULL AND tenant_user_roles.deleted_at IS NULL AND r.name IN ? AND `+activeWindowClause("tenant_user_roles")+tenantFilter+userFilter)
+		args = append(args, roleNames, now, now)
+		args = append(args, tenantArgs...)
+		args = append(args, userArgs...)
+	}
+
+	if filter.Scope == "" || filter.Scope == port.RoleOriginBinding {
+		parts = append(parts, `SELECT u.uid AS user_uid, u.username, u.email, r.name AS role_name, 'binding' AS scope,
+			NULL AS tenant_uid, b.resource_type, b.resource_id
+		FROM resource_role_bindings b JOIN users u ON u.uid = b.user_uid JOIN roles r ON r.uid = b.role_uid
+		WHERE u.deleted_at IS NULL AND r.deleted_at IS NULL AND b.deleted_at IS NULL AND r.name IN ?`+userFilter)
+		args = append(args, roleNames)
+		args = append(args, userArgs...)
+	}
+
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("unknown scope %q", filter.Scope)
+	}
+
+	var rows []port.RoleHolderRow
+	query := strings.Join(parts, "\nUNION ALL\n") + "\nORDER BY username, role_name, scope"
+	if err := dbFrom(ctx, r.db).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *roleRepo) GetRoleByUID(ctx context.Context, uid string) (*domain.Role, error) {
+	var role domain.Role
+	if err := dbFrom(ctx, r.db).Where("uid = ?", uid).First(&role).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &role, nil
+}
+
+func (r *roleRepo) Update(ctx context.Context, role *domain.Role) error {
+	return translateError(dbFrom(ctx, r.db).Model(role).Select("name", "description").Updates(role).Error)
+}
+
+// roleCascadeSQL ความสัมพันธ์ทั้งหมดที่อ้างถึง Role (ทุกตารางเป็น Hard Delete เพราะมี Unique Index)
+var roleCascadeSQL = []string{
+	"DELETE FROM role_permissions WHERE role_uid = @uid",
+	"DELETE FROM user_roles WHERE role_uid = @uid",
+	"DELETE FROM tenant_user_roles WHERE role_uid = @uid",
+	"DELETE FROM resource_role_bindings WHERE role_uid = @uid",
+	"DELETE FROM role_parents WHERE role_uid = @uid OR parent_uid = @uid",
+	"DELETE FROM role_deny_rules WHERE role_uid = @uid",
+}
+
+func (r *roleRepo) Delete(ctx context.Context, uid string, cascade bool) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var cascadeSQL []string
+		if cascade {
+			cascadeSQL = roleCascadeSQL
+		}
+		return softDelete(tx, &domain.Role{}, uid, cascadeSQL)
+	})
+}
+
+func (r *roleRepo) Restore(ctx context.Context, uid string) error {
+	return restore(dbFrom(ctx, r.db), &domain.Role{}, uid)
+}
+
+func (r *roleRepo) GetDeleted(ctx context.Context) ([]domain.Role, error) {
+	var roles []domain.Role
+	if err := dbFrom(ctx, r.db).Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
 }
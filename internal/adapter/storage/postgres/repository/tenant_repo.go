@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tenantRepo struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) port.TenantRepository {
+	return &tenantRepo{db: db}
+}
+
+func (r *tenantRepo) Create(ctx context.Context, tenant *domain.Tenant) error {
+	return dbFrom(ctx, r.db).Create(tenant).Error
+}
+
+func (r *tenantRepo) GetAll(ctx context.Context) ([]domain.Tenant, error) {
+	var tenants []domain.Tenant
+	err := dbFrom(ctx, r.db).Find(&tenants).Error
+	if err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+func (r *tenantRepo) GetTenantByUID(ctx context.Context, uid string) (*domain.Tenant, error) {
+	var tenant domain.Tenant
+	err := dbFrom(ctx, r.db).Where("uid = ?", uid).First(&tenant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *tenantRepo) AddRoleAssignment(ctx context.Context, assignment *domain.TenantRoleAssignment) error {
+	// ถ้ามีอยู่แล้วให้อัปเดตช่วงเวลา
+	return dbFrom(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_uid"}, {Name: "user_uid"}, {Name: "role_uid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"valid_from", "valid_until", "updated_at"}),
+	}).Create(assignment).Error
+}
+
+func (r *tenantRepo) RemoveRoleAssignment(ctx context.Context, tenantID string, userID string, roleID string) error {
+	// ลบจริง (Unscoped) เพราะมี Unique Index ถ้า Soft Delete จะ Assign ซ้ำไม่ได้
+	res := dbFrom(ctx, r.db).Unscoped().
+		Where("tenant_uid = ? AND user_uid = ? AND role_uid = ?", tenantID, userID, roleID).
+		Delete(&domain.TenantRoleAssignment{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("user does not have role in tenant: %s", tenantID)
+	}
+	return nil
+}
+
+func (r *tenantRepo) GetAssignmentsByUserUID(ctx context.Context, userID string) ([]domain.TenantRoleAssignment, error) {
+	var assignments []domain.TenantRoleAssignment
+	err := dbFrom(ctx, r.db).
+		Preload("Tenant").
+		Preload("Role").
+		Where("user_uid = ?", userID).
+		Find(&assignments).Error
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+func (r *tenantRepo) GetRolesByTenantAndUser(ctx context.Context, tenantID string, userID string) ([]domain.Role, error) {
+	var roles []domain.Role
+	err := dbFrom(ctx, r.db).
+		Joins("JOIN tenant_user_roles ON tenant_user_roles.role_uid = roles.uid").
+		Where("tenant_user_roles.tenant_uid = ? AND tenant_user_roles.user_uid = ?", tenantID, userID).
+		Where(activeWindowClause("tenant_user_roles"), time.Now(), time.Now()).
+		Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *tenantRepo) IsMember(ctx context.Context, tenantID string, userID string) (bool, error) {
+	var count int64
+	err := dbFrom(ctx, r.db).
+		Model(&domain.TenantRoleAssignment{}).
+		Where("tenant_uid = ? AND user_uid = ?", tenantID, userID).
+		Where(activeWindowClause("tenant_user_roles"), time.Now(), time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *tenantRepo) GetNextRoleChange(ctx context.Context, tenantID string, userID string, now time.Time) (*time.Time, error) {
+	return nextWindowChange(ctx, r.db, "tenant_user_roles", "tenant_uid = ? AND user_uid = ?", []interface{}{tenantID, userID}, now)
+}
+
+func (r *tenantRepo) DeleteExpiredAssignments(ctx context.Context, now time.Time) ([]domain.TenantRoleAssignment, error) {
+	var removed []domain.TenantRoleAssignment
+	err := dbFrom(ctx, r.db).Unscoped().
+		Clauses(clause.Returning{}).
+		Where("valid_until IS NOT NULL AND valid_until <= ?", now).
+		Delete(&removed).Error
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
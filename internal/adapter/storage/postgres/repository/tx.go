@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"gorm.io/gorm"
+)
+
+type txCtxKey struct{}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) port.Transactor {
+	return &transactor{db: db}
+}
+
+// WithinTx ซ้อนกันได้: ถ้า ctx อยู่ใน Transaction แล้วใช้ตัวเดิม (Commit / Rollback ตามตัวนอกสุด)
+func (t *transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txCtxKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txCtxKey{}, tx))
+	})
+}
+
+// dbFrom ทุก Repository ต้องเอา DB จากตรงนี้ จะได้เข้าร่วม Transaction ของ WithinTx ถ้ามี
+func dbFrom(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txCtxKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -3,10 +3,12 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type userRepo struct {
@@ -18,54 +20,138 @@ func NewUserRepository(db *gorm.DB) port.UserRepository {
 }
 
 func (r *userRepo) Create(ctx context.Context, user *domain.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return translateError(dbFrom(ctx, r.db).Create(user).Error)
 }
 
 func (r *userRepo) GetUserByUID(ctx context.Context, uid string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.WithContext(ctx).Where("uid = ?", uid).First(&user).Error
+	err := dbFrom(ctx, r.db).Where("uid = ?", uid).First(&user).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	return &user, nil
 }
 
 func (r *userRepo) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
+	err := dbFrom(ctx, r.db).Where("username = ?", username).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepo) AddAccosiateRole(ctx context.Context, userID string, roleID string) error {
+func (r *userRepo) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	var user domain.User
+	err := dbFrom(ctx, r.db).Where("LOWER(email) = LOWER(?)", email).First(&user).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepo) AddAccosiateRole(ctx context.Context, userID string, roleID string, validFrom *time.Time, validUntil *time.Time) error {
 	// หา User และ Role
 	var user domain.User
-	if err := r.db.WithContext(ctx).Where("uid = ?", userID).First(&user).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Where("uid = ?", userID).First(&user).Error; err != nil {
 		return err
 	}
 	var role domain.Role
-	if err := r.db.WithContext(ctx).Where("uid = ?", roleID).First(&role).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Where("uid = ?", roleID).First(&role).Error; err != nil {
 		return err
 	}
-	// จับคู่ User <-> Role
-	return r.db.WithContext(ctx).Model(&user).Association("Roles").Append(&role)
+	// จับคู่ User <-> Role (ถ้ามีอยู่แล้วให้อัปเดตช่วงเวลา)
+	assignment := domain.UserRole{
+		UserUid:    user.Uid,
+		RoleUid:    role.Uid,
+		ValidFrom:  validFrom,
+		ValidUntil: validUntil,
+	}
+	return dbFrom(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_uid"}, {Name: "role_uid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"valid_from", "valid_until"}),
+	}).Create(&assignment).Error
 }
 
 func (r *userRepo) RemoveAssociateRole(ctx context.Context, userID string, roleID string) error {
 	var user domain.User
-	if err := r.db.WithContext(ctx).Where("uid = ?", userID).First(&user).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Where("uid = ?", userID).First(&user).Error; err != nil {
 		return err
 	}
 	var role domain.Role
-	if err := r.db.WithContext(ctx).Where("uid = ?", roleID).First(&role).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Where("uid = ?", roleID).First(&role).Error; err != nil {
 		return err
 	}
-	count := r.db.WithContext(ctx).Model(&user).Where("uid = ?", roleID).Association("Roles").Count()
+	count := dbFrom(ctx, r.db).Model(&user).Where("uid = ?", roleID).Association("Roles").Count()
 	if count == 0 {
 		return fmt.Errorf("user does not have role: %s", role.Name)
 	}
 	// ลบความสัมพันธ์ในตาราง user_roles
-	return r.db.WithContext(ctx).Model(&user).Association("Roles").Delete(&role)
+	return dbFrom(ctx, r.db).Model(&user).Association("Roles").Delete(&role)
+}
+
+func (r *userRepo) DeleteExpiredRoles(ctx context.Context, now time.Time) ([]domain.UserRole, error) {
+	var removed []domain.UserRole
+	err := dbFrom(ctx, r.db).
+		Clauses(clause.Returning{}).
+		Where("valid_until IS NOT NULL AND valid_until <= ?", now).
+		Delete(&removed).Error
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// List สถานะ deleted ต้อง Unscoped ถึงจะเห็น Record ที่ถูก Soft Delete
+func (r *userRepo) List(ctx context.Context, filter port.UserFilter) ([]domain.User, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&domain.User{})
+	switch filter.Status {
+	case port.UserStatusActive:
+		q = q.Where("disabled_at IS NULL")
+	case port.UserStatusDisabled:
+		q = q.Where("disabled_at IS NOT NULL")
+	case port.UserStatusDeleted:
+		q = q.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if filter.Kind != "" {
+		q = q.Where("kind = ?", filter.Kind)
+	}
+	if filter.Search != "" {
+		like := "%" + filter.Search + "%"
+		q = q.Where("(username ILIKE ? OR email ILIKE ?)", like, like)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []domain.User
+	err := q.Order("username").Limit(filter.Limit).Offset(filter.Offset).Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *userRepo) Update(ctx context.Context, user *domain.User) error {
+	res := dbFrom(ctx, r.db).Model(user).
+		Select("username", "email", "email_verified_at", "password", "disabled_at", "must_change_password").
+		Updates(user)
+	if res.Error != nil {
+		return translateError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete เป็น Soft Delete และเก็บ Role ที่ได้รับไว้ (Restore แล้วได้สิทธิ์เดิมกลับมา)
+func (r *userRepo) Delete(ctx context.Context, uid string) error {
+	return softDelete(dbFrom(ctx, r.db), &domain.User{}, uid, nil)
+}
+
+func (r *userRepo) Restore(ctx context.Context, uid string) error {
+	return restore(dbFrom(ctx, r.db), &domain.User{}, uid)
 }
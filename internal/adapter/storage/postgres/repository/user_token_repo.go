@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"gorm.io/gorm"
+)
+
+type userTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) port.UserTokenRepository {
+	return &userTokenRepo{db: db}
+}
+
+func (r *userTokenRepo) Create(ctx context.Context, token *domain.UserToken) error {
+	return dbFrom(ctx, r.db).Create(token).Error
+}
+
+func (r *userTokenRepo) GetByHash(ctx context.Context, purpose string, hash string) (*domain.UserToken, error) {
+	var token domain.UserToken
+	err := dbFrom(ctx, r.db).Where("purpose = ? AND token_hash = ?", purpose, hash).First(&token).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &token, nil
+}
+
+func (r *userTokenRepo) MarkUsed(ctx context.Context, uid string) error {
+	// เงื่อนไข used_at IS NULL กัน Request ซ้อนกันใช้ Token เดียวกันได้ 2 ครั้ง
+	res := dbFrom(ctx, r.db).
+		Model(&domain.UserToken{}).
+		Where("uid = ? AND used_at IS NULL", uid).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrInvalidUserToken
+	}
+	return nil
+}
+
+func (r *userTokenRepo) InvalidateForUser(ctx context.Context, userID string, purpose string) error {
+	return dbFrom(ctx, r.db).
+		Model(&domain.UserToken{}).
+		Where("user_uid = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
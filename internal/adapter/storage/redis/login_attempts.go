@@ -0,0 +1,78 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/redis/go-redis/v9"
+)
+
+// loginAttemptStore ใช้ Redis ร่วมกันทุก Instance (Attacker สลับ Instance ก็ยังนับรวม)
+// ถ้า Redis ล่มจะไม่นับ (Fail Open) เพื่อไม่ให้ทุกคน Login ไม่ได้
+type loginAttemptStore struct {
+	client *redis.Client
+}
+
+func NewLoginAttemptStore(client *redis.Client) port.LoginAttemptStore {
+	return &loginAttemptStore{client: client}
+}
+
+func (s *loginAttemptStore) Failures(ctx context.Context, key string) (int64, time.Duration, error) {
+	pipe := s.client.Pipeline()
+	get := pipe.Get(ctx, loginAttemptKey(key))
+	ttl := pipe.PTTL(ctx, loginAttemptKey(key))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		log.Printf("⚠️ Redis error on login attempts lookup: %v (not throttled)", err)
+		return 0, 0, nil
+	}
+
+	count, err := get.Int64()
+	if err != nil {
+		return 0, 0, nil // ไม่มี Key = ยังไม่เคยผิด
+	}
+	return count, ttl.Val(), nil
+}
+
+func (s *loginAttemptStore) RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	k := loginAttemptKey(key)
+	count, err := s.client.Incr(ctx, k).Result()
+	if err != nil {
+		log.Printf("⚠️ Redis error on record login failure: %v", err)
+		return 0, nil
+	}
+	// ตั้งอายุครั้งแรกเท่านั้น (ไม่ยืด Window ทุกครั้งที่ผิด)
+	if count == 1 {
+		if err := s.client.Expire(ctx, k, window).Err(); err != nil {
+			log.Printf("⚠️ Redis error on set login failure window: %v", err)
+		}
+	}
+	return count, nil
+}
+
+func (s *loginAttemptStore) Lock(ctx context.Context, key string, d time.Duration) error {
+	if err := s.client.Expire(ctx, loginAttemptKey(key), d).Err(); err != nil {
+		log.Printf("⚠️ Redis error on lock login: %v", err)
+	}
+	return nil
+}
+
+func (s *loginAttemptStore) Reset(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	redisKeys := make([]string, 0, len(keys))
+	for _, k := range keys {
+		redisKeys = append(redisKeys, loginAttemptKey(k))
+	}
+	if err := s.client.Del(ctx, redisKeys...).Err(); err != nil {
+		return fmt.Errorf("reset login attempts: %w", err)
+	}
+	return nil
+}
+
+func loginAttemptKey(key string) string {
+	return fmt.Sprintf("rbac:login:failures:%s", key)
+}
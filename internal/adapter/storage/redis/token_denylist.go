@@ -0,0 +1,187 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenDenylist เขียนลง Redis เป็นหลัก และเขียนสำเนาไว้ใน Memory ด้วยเสมอ
+// ถ้า Redis ล่ม อย่างน้อย Instance นี้ยังปฏิเสธ Token ที่ถูกเพิกถอนผ่านตัวมันเองได้
+type tokenDenylist struct {
+	client *redis.Client
+	local  *memoryStore
+}
+
+func NewTokenDenylist(client *redis.Client) port.TokenDenylist {
+	return &tokenDenylist{client: client, local: newMemoryStore()}
+}
+
+func (d *tokenDenylist) Deny(ctx context.Context, jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil // Token หมดอายุไปแล้ว ไม่ต้องเก็บ
+	}
+	key := deniedTokenKey(jti)
+	d.local.set(key, "1", ttl)
+
+	if err := d.client.Set(ctx, key, "1", ttl).Err(); err != nil {
+		log.Printf("⚠️ Redis error on deny token: %v (kept in memory only)", err)
+	}
+	return nil
+}
+
+func (d *tokenDenylist) IsDenied(ctx context.Context, jti string) (bool, error) {
+	key := deniedTokenKey(jti)
+	if _, ok := d.local.get(key); ok {
+		return true, nil
+	}
+
+	n, err := d.client.Exists(ctx, key).Result()
+	if err != nil {
+		log.Printf("⚠️ Redis error on denylist lookup: %v (using memory only)", err)
+		return false, nil
+	}
+	return n > 0, nil
+}
+
+func (d *tokenDenylist) RevokeUser(ctx context.Context, userID string, at time.Time, ttl time.Duration) error {
+	key := revokedBeforeKey(userID)
+	value := strconv.FormatInt(at.Unix(), 10)
+	d.local.set(key, value, ttl)
+
+	if err := d.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		log.Printf("⚠️ Redis error on revoke user sessions: %v (kept in memory only)", err)
+	}
+	return nil
+}
+
+func (d *tokenDenylist) RevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	key := revokedBeforeKey(userID)
+
+	var latest int64
+	if v, ok := d.local.get(key); ok {
+		latest, _ = strconv.ParseInt(v, 10, 64)
+	}
+
+	val, err := d.client.Get(ctx, key).Result()
+	if err == nil {
+		if ts, err := strconv.ParseInt(val, 10, 64); err == nil && ts > latest {
+			latest = ts
+		}
+	} else if err != redis.Nil {
+		log.Printf("⚠️ Redis error on revoked-before lookup: %v (using memory only)", err)
+	}
+
+	if latest == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(latest, 0), nil
+}
+
+// SetUserDisabled Marker ไม่มี TTL ใน Redis (อยู่จนกว่าจะ Enable)
+// สำเนาใน Memory มีอายุจำกัด เพราะใช้แค่ตอน Redis ล่มเท่านั้น
+func (d *tokenDenylist) SetUserDisabled(ctx context.Context, userID string, disabled bool) error {
+	key := userDisabledKey(userID)
+
+	if !disabled {
+		d.local.delete(key)
+		if err := d.client.Del(ctx, key).Err(); err != nil {
+			return fmt.Errorf("clear disabled marker: %w", err)
+		}
+		return nil
+	}
+
+	d.local.set(key, "1", disabledLocalTTL)
+	if err := d.client.Set(ctx, key, "1", 0).Err(); err != nil {
+		log.Printf("⚠️ Redis error on disable user: %v (kept in memory only)", err)
+	}
+	return nil
+}
+
+// IsUserDisabled ถาม Redis ก่อน (Instance อื่นอาจ Enable ไปแล้ว) ใช้ Memory เฉพาะตอน Redis ล่ม
+func (d *tokenDenylist) IsUserDisabled(ctx context.Context, userID string) (bool, error) {
+	key := userDisabledKey(userID)
+
+	n, err := d.client.Exists(ctx, key).Result()
+	if err != nil {
+		log.Printf("⚠️ Redis error on disabled lookup: %v (using memory only)", err)
+		_, ok := d.local.get(key)
+		return ok, nil
+	}
+	return n > 0, nil
+}
+
+const disabledLocalTTL = 24 * time.Hour
+
+func deniedTokenKey(jti string) string {
+	return fmt.Sprintf("rbac:token:denied:%s", jti)
+}
+
+func revokedBeforeKey(userID string) string {
+	return fmt.Sprintf("rbac:user:%s:revoked_before", userID)
+}
+
+func userDisabledKey(userID string) string {
+	return fmt.Sprintf("rbac:user:%s:disabled", userID)
+}
+
+// --- memoryStore: Key/Value ที่มี TTL แบบง่ายๆ ใช้เป็น Fallback ตอน Redis ล่ม ---
+type memoryEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{entries: make(map[string]memoryEntry)}
+}
+
+func (m *memoryStore) set(key string, value string, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = memoryEntry{value: value, expiresAt: time.Now().Add(ttl)}
+	m.pruneLocked()
+}
+
+func (m *memoryStore) get(key string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(m.entries, key)
+		return "", false
+	}
+	return e.value, true
+}
+
+func (m *memoryStore) delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+}
+
+// pruneLocked ลบ Entry ที่หมดอายุทิ้ง (เรียกตอน set เพื่อไม่ให้ Map โตไม่หยุด)
+func (m *memoryStore) pruneLocked() {
+	now := time.Now()
+	for k, e := range m.entries {
+		if now.After(e.expiresAt) {
+			delete(m.entries, k)
+		}
+	}
+}
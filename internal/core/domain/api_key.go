@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey ของ Service Account: Key จริงคือ "<Prefix>_<Secret>" เก็บแค่ Prefix (ไว้ค้นหา / แสดง) กับ Hash
+type APIKey struct {
+	Model
+	UserUid    uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_uid"`
+	Name       string     `gorm:"size:255;not null" json:"name"`
+	Prefix     string     `gorm:"uniqueIndex;not null;size:32" json:"prefix"`
+	KeyHash    string     `gorm:"not null;size:64" json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // nil = ไม่หมดอายุ (Rotate แบบมี Grace จะตั้งให้ Key เก่า)
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// Active ใช้ได้อยู่ ณ เวลา now
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// --- Audit Actions ---
+const (
+	// RBAC
+	AuditRoleCreate           = "role.create"
+	AuditRoleUpdate           = "role.update"
+	AuditRoleDelete           = "role.delete"
+	AuditRoleRestore          = "role.restore"
+	AuditRolePermissionAssign = "role.permission.assign"
+	AuditRolePermissionRemove = "role.permission.remove"
+	AuditRoleParentAssign     = "role.parent.assign"
+	AuditRoleParentRemove     = "role.parent.remove"
+	AuditRoleDenyAdd          = "role.deny.add"
+	AuditRoleDenyRemove       = "role.deny.remove"
+	AuditPermissionCreate     = "permission.create"
+	AuditPermissionUpdate     = "permission.update"
+	AuditPermissionDelete     = "permission.delete"
+	AuditPermissionRestore    = "permission.restore"
+	AuditUserRoleAssign       = "user.role.assign"
+	AuditUserRoleRemove       = "user.role.remove"
+	AuditUserRoleExpire       = "user.role.expire"
+	AuditUserBindingAdd       = "user.binding.add"
+	AuditUserBindingRemove    = "user.binding.remove"
+	AuditUserDenyAdd          = "user.deny.add"
+	AuditUserDenyRemove       = "user.deny.remove"
+	AuditTenantCreate         = "tenant.create"
+	AuditTenantRoleAssign     = "tenant.role.assign"
+	AuditTenantRoleRemove     = "tenant.role.remove"
+	AuditAccessDenied         = "access.denied" // สุ่มเก็บ (audit.denied_sample_rate)
+
+	// User / Service Account
+	AuditUserUpdate             = "user.update"
+	AuditUserDisable            = "user.disable"
+	AuditUserEnable             = "user.enable"
+	AuditUserDelete             = "user.delete"
+	AuditUserRestore            = "user.restore"
+	AuditUserForcePasswordReset = "user.force_password_reset"
+	AuditServiceAccountCreate   = "service_account.create"
+	AuditServiceAccountDelete   = "service_account.delete"
+	AuditAPIKeyCreate           = "api_key.create"
+	AuditAPIKeyRotate           = "api_key.rotate"
+	AuditAPIKeyRevoke           = "api_key.revoke"
+
+	// Auth
+	AuditLogin                = "auth.login"
+	AuditLoginMFA             = "auth.login.mfa"
+	AuditLogout               = "auth.logout"
+	AuditRefreshTokenReuse    = "auth.refresh_token.reuse"
+	AuditSessionsRevoke       = "auth.sessions.revoke"
+	AuditAccountUnlock        = "auth.account.unlock"
+	AuditPasswordChange       = "auth.password.change"
+	AuditPasswordResetRequest = "auth.password.reset_request"
+	AuditPasswordReset        = "auth.password.reset"
+	AuditEmailVerify          = "auth.email.verify"
+	AuditMFAEnable            = "auth.mfa.enable"
+	AuditMFADisable           = "auth.mfa.disable"
+	AuditMFARecoveryCodes     = "auth.mfa.recovery_codes"
+)
+
+// Outcome ของ Audit Event
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+	AuditDenied  = "denied"
+)
+
+// ActorType บอกว่าใครเป็นคนทำ
+const (
+	AuditActorUser      = "user"
+	AuditActorService   = "service"   // Service Account (X-API-Key)
+	AuditActorClient    = "client"    // Service ที่เรียก Decision API (HTTP Basic)
+	AuditActorSystem    = "system"    // Background Job เช่น Sweeper
+	AuditActorAnonymous = "anonymous" // ยังไม่ได้ Login (เช่น Login / ลืมรหัสผ่าน)
+)
+
+// AuditEvent บันทึกแบบต่อท้ายอย่างเดียว (ไม่มี Update / Delete)
+// ทุกรายการเก็บ Hash ของรายการก่อนหน้า แก้ / ลบ / สลับรายการไหนก็ตาม Chain จะขาดตั้งแต่จุดนั้น
+type AuditEvent struct {
+	Seq        int64     `gorm:"primaryKey;autoIncrement:false" json:"seq"`
+	OccurredAt time.Time `gorm:"not null;index" json:"occurred_at"`
+	ActorID    string    `gorm:"size:255;index" json:"actor_id,omitempty"`
+	ActorType  string    `gorm:"size:20;not null" json:"actor_type"`
+	Action     string    `gorm:"size:64;not null;index" json:"action"`
+	TargetType string    `gorm:"size:32;index:idx_audit_target" json:"target_type,omitempty"`
+	TargetID   string    `gorm:"size:255;index:idx_audit_target" json:"target_id,omitempty"`
+	Outcome    string    `gorm:"size:16;not null" json:"outcome"`
+	Before     string    `gorm:"type:text" json:"before,omitempty"` // JSON
+	After      string    `gorm:"type:text" json:"after,omitempty"`  // JSON
+	TenantID   string    `gorm:"size:64" json:"tenant_id,omitempty"`
+	IP         string    `gorm:"size:64" json:"ip,omitempty"`
+	RequestID  string    `gorm:"size:128;index" json:"request_id,omitempty"`
+	PrevHash   string    `gorm:"size:64;not null" json:"prev_hash"`
+	Hash       string    `gorm:"size:64;not null;uniqueIndex" json:"hash"`
+}
+
+// ComputeHash = SHA-256(PrevHash + ข้อมูลทุก Field ยกเว้น Hash)
+// OccurredAt ต้องตัดเหลือระดับ Microsecond ก่อนบันทึก (Postgres เก็บได้แค่นั้น) ไม่งั้นตรวจทีหลังจะไม่ตรง
+func (e *AuditEvent) ComputeHash() string {
+	payload, _ := json.Marshal(struct {
+		Seq        int64  `json:"seq"`
+		OccurredAt string `json:"occurred_at"`
+		ActorID    string `json:"actor_id"`
+		ActorType  string `json:"actor_type"`
+		Action     string `json:"action"`
+		TargetType string `json:"target_type"`
+		TargetID   string `json:"target_id"`
+		Outcome    string `json:"outcome"`
+		Before     string `json:"before"`
+		After      string `json:"after"`
+		TenantID   string `json:"tenant_id"`
+		IP         string `json:"ip"`
+		RequestID  string `json:"request_id"`
+	}{
+		Seq:        e.Seq,
+		OccurredAt: e.OccurredAt.UTC().Format(time.RFC3339Nano),
+		ActorID:    e.ActorID,
+		ActorType:  e.ActorType,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Outcome:    e.Outcome,
+		Before:     e.Before,
+		After:      e.After,
+		TenantID:   e.TenantID,
+		IP:         e.IP,
+		RequestID:  e.RequestID,
+	})
+
+	h := sha256.New()
+	h.Write([]byte(e.PrevHash))
+	h.Write([]byte{'\n'})
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
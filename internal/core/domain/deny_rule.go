@@ -0,0 +1,18 @@
+package domain
+
+import "github.com/google/uuid"
+
+// RoleDenyRule ห้ามผู้ถือ Role นี้ใช้ Permission (รวมถึง Role ลูกที่สืบทอดไป)
+// Deny ชนะ Allow เสมอ แม้ Role อื่นของ User จะให้สิทธิ์ไว้ก็ตาม
+type RoleDenyRule struct {
+	Model
+	RoleUid    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_role_deny" json:"role_uid"`
+	Permission string    `gorm:"size:255;not null;uniqueIndex:idx_role_deny" json:"permission"` // ใช้ Wildcard ได้ เช่น "billing:*"
+}
+
+// UserDenyRule ห้าม User คนนี้ใช้ Permission ไม่ว่าจะถือ Role อะไร
+type UserDenyRule struct {
+	Model
+	UserUid    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_deny" json:"user_uid"`
+	Permission string    `gorm:"size:255;not null;uniqueIndex:idx_user_deny" json:"permission"`
+}
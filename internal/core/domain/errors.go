@@ -0,0 +1,79 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrRoleCycle ถูกคืนเมื่อการผูก Parent จะทำให้ Role Hierarchy วนกลับมาหาตัวเอง
+	ErrRoleCycle = errors.New("role hierarchy would contain a cycle")
+
+	ErrInvalidPermissionName = errors.New("invalid permission name")
+	ErrInvalidRoleName       = errors.New("invalid role name")
+
+	// --- Repository (แปลงจาก Error ของ GORM) ---
+	ErrNotFound      = errors.New("record not found")
+	ErrAlreadyExists = errors.New("record already exists")
+
+	// ErrInvalidValidityWindow: valid_until ต้องอยู่หลัง valid_from และยังไม่ผ่านไปแล้ว
+	ErrInvalidValidityWindow = errors.New("invalid validity window")
+
+	// ErrInvalidCondition: เงื่อนไขของ Role-Permission เขียนผิด Syntax หรือใช้ค่าที่ไม่รองรับ
+	ErrInvalidCondition = errors.New("invalid condition")
+
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrTooManyLoginAttempts ไม่บอกว่า Username มีอยู่จริงหรือไม่ (ล็อกได้แม้ Username ไม่มีในระบบ)
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+
+	// --- MFA ---
+	ErrInvalidMFAChallenge = errors.New("invalid or expired MFA challenge")
+	ErrInvalidMFACode      = errors.New("invalid MFA code")
+	ErrMFAAlreadyEnabled   = errors.New("MFA is already enabled")
+	ErrMFANotEnrolled      = errors.New("MFA enrollment has not been started")
+	// ErrMFARequired: Role / Permission ที่ถือบังคับให้ต้องมี MFA (ปิดไม่ได้ / ต้องลงทะเบียนก่อน)
+	ErrMFARequired = errors.New("MFA is required for this account")
+
+	// --- User Account ---
+	ErrUserDisabled           = errors.New("account is disabled")
+	ErrPasswordChangeRequired = errors.New("password change required")
+
+	// ErrValidation Input ไม่ผ่านการตรวจ (ดูรายละเอียดราย Field ใน *ValidationError)
+	ErrValidation = errors.New("validation failed")
+
+	// ErrInvalidQuery: Filter / Pagination ของ Endpoint แบบ List ไม่ถูกต้อง
+	ErrInvalidQuery = errors.New("invalid query")
+
+	// ErrAuditWriteFailed: เขียน Audit Log ไม่สำเร็จ (การแก้ไขที่อยู่ใน Transaction เดียวกันถูก Rollback แล้ว)
+	ErrAuditWriteFailed = errors.New("audit log write failed")
+
+	// --- Refresh Token ---
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused หมายถึงมีคนเอา Refresh Token ที่ถูก Rotate ไปแล้วกลับมาใช้ซ้ำ (น่าจะโดนขโมย)
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+	// --- Access Token Verification (Middleware จะแปลงเป็น 401 แยกตามชนิด) ---
+	ErrTokenMissing          = errors.New("missing bearer token")
+	ErrTokenMalformed        = errors.New("malformed token")
+	ErrTokenAlgorithm        = errors.New("token signing algorithm not allowed")
+	ErrTokenUnknownKey       = errors.New("token signed with unknown key")
+	ErrTokenSignatureInvalid = errors.New("token signature is invalid")
+	ErrTokenExpired          = errors.New("token is expired")
+	ErrTokenNotYetValid      = errors.New("token is not valid yet")
+	ErrTokenClaimsInvalid    = errors.New("token claims are invalid")
+	ErrTokenRevoked          = errors.New("token has been revoked")
+
+	// --- Password Reset / Email Verification ---
+	ErrInvalidUserToken     = errors.New("invalid or expired token")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	ErrTooManyTokenRequests = errors.New("too many requests, try again later")
+
+	// --- Service Account / API Key ---
+	ErrInvalidAPIKey     = errors.New("invalid api key")
+	ErrNotServiceAccount = errors.New("user is not a service account")
+
+	// --- Policy Decision API ---
+	ErrInvalidClientCredentials = errors.New("invalid client credentials")
+	ErrInvalidAuthzRequest      = errors.New("invalid authorization request")
+
+	// --- Tenant ---
+	ErrNotTenantMember = errors.New("user is not a member of this tenant")
+	ErrTenantMismatch  = errors.New("tenant does not match the token")
+)
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MFACredential TOTP Secret ของ User (ConfirmedAt เป็น nil = เริ่มลงทะเบียนแล้วแต่ยังไม่ยืนยัน Code แรก)
+type MFACredential struct {
+	UserUid     uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_uid"`
+	Secret      string     `gorm:"size:64;not null" json:"-"` // Base32
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	// LastUsedStep Time Step ล่าสุดที่ใช้ไปแล้ว กัน Code เดิมถูกใช้ซ้ำภายใน 30 วินาที
+	LastUsedStep int64     `gorm:"not null;default:0" json:"-"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// RecoveryCode ใช้แทน TOTP ได้ครั้งเดียว (เก็บแค่ Hash)
+type RecoveryCode struct {
+	Model
+	UserUid  uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_uid"`
+	CodeHash string     `gorm:"uniqueIndex;not null;size:64" json:"-"`
+	UsedAt   *time.Time `json:"used_at,omitempty"`
+}
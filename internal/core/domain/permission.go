@@ -1,8 +1,67 @@
 package domain
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PermissionWildcard ใช้ได้ทั้งแบบ "*" (Superuser) และเป็นชั้นสุดท้าย เช่น "report:*"
+const PermissionWildcard = "*"
+
 type Permission struct {
 	Model
-	Name string `gorm:"uniqueIndex;not null;size:255" json:"name"`
+	// ชื่อห้ามซ้ำเฉพาะแถวที่ยังไม่ถูกลบ (ลบแล้วสร้างชื่อเดิมใหม่ได้ ส่วน Restore ตัวเก่าทีหลังจะได้ 409)
+	// DB เดิมต้อง DROP INDEX idx_permissions_name (Unique แบบเต็มตาราง) เอง AutoMigrate ไม่ลบ Index เก่าให้
+	Name        string `gorm:"uniqueIndex:idx_permissions_name_active,where:deleted_at IS NULL;not null;size:255" json:"name"`
+	Description string `gorm:"size:1000" json:"description"`
 
 	Roles []*Role `gorm:"many2many:role_permissions;" json:"-"`
 }
+
+// permissionSegment: แต่ละชั้นของชื่อ Permission (คั่นด้วย ":")
+var permissionSegment = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidatePermissionName ชื่อต้องเป็น "resource:action[:...]" ตัวพิมพ์เล็ก อย่างน้อย 2 ชั้น
+// "*" ใช้ได้เฉพาะชั้นสุดท้าย (หรือทั้งชื่อ ซึ่งเป็นชื่อชั้นเดียวที่ยอมให้)
+func ValidatePermissionName(name string) error {
+	if name == PermissionWildcard {
+		return nil
+	}
+	if len(name) > 255 {
+		return fmt.Errorf("%w: too long", ErrInvalidPermissionName)
+	}
+
+	segments := strings.Split(name, ":")
+	if len(segments) < 2 {
+		return fmt.Errorf("%w: %q (expected resource:action)", ErrInvalidPermissionName, name)
+	}
+	for i, seg := range segments {
+		if seg == PermissionWildcard && i == len(segments)-1 && i > 0 {
+			continue
+		}
+		if !permissionSegment.MatchString(seg) {
+			return fmt.Errorf("%w: %q", ErrInvalidPermissionName, name)
+		}
+	}
+	return nil
+}
+
+// IsWildcardPermission คืน true ถ้าชื่อนี้ครอบคลุม Permission อื่น ("*" หรือ "xxx:*")
+func IsWildcardPermission(name string) bool {
+	return name == PermissionWildcard || strings.HasSuffix(name, ":"+PermissionWildcard)
+}
+
+// PermissionMatches เช็คว่า granted (อาจเป็น Wildcard) ครอบคลุม required หรือไม่
+//   - "*"          ครอบคลุมทุกอย่าง
+//   - "report:*"   ครอบคลุม "report:view", "report:export:csv" (ทุกชั้นที่อยู่ใต้ report)
+//   - อื่นๆ        ต้องตรงกันทุกตัวอักษร
+func PermissionMatches(granted string, required string) bool {
+	if granted == PermissionWildcard || granted == required {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(granted, PermissionWildcard); ok && strings.HasSuffix(prefix, ":") {
+		return strings.HasPrefix(required, prefix)
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidatePermissionName(t *testing.T) {
+	valid := []string{
+		"*",
+		"report:view",
+		"report:*",
+		"report:export:csv",
+		"billing:*",
+		"system:admin",
+		"project-x:read_only",
+		"v2:item",
+		"dashboard:view",
+	}
+	for _, name := range valid {
+		t.Run(name, func(t *testing.T) {
+			if err := ValidatePermissionName(name); err != nil {
+				t.Errorf("ValidatePermissionName(%q) = %v, want nil", name, err)
+			}
+		})
+	}
+
+	invalid := []string{
+		"",
+		"dashboard",
+		"report",
+		"Report:view",
+		"report:View",
+		"report view",
+		"report:",
+		":view",
+		"report::view",
+		"*:view",
+		"report:*:csv",
+		"report:v*",
+		"**",
+		"_report:view",
+		"-report:view",
+		"report:view!",
+		"report:" + strings.Repeat("a", 255),
+	}
+	for _, name := range invalid {
+		t.Run(name, func(t *testing.T) {
+			err := ValidatePermissionName(name)
+			if !errors.Is(err, ErrInvalidPermissionName) {
+				t.Errorf("ValidatePermissionName(%q) = %v, want ErrInvalidPermissionName", name, err)
+			}
+		})
+	}
+}
+
+func TestPermissionMatches(t *testing.T) {
+	tests := []struct {
+		granted  string
+		required string
+		want     bool
+	}{
+		// ตรงกันทุกตัวอักษร
+		{"report:view", "report:view", true},
+		{"report:view", "report:export", false},
+		{"report:view", "report:view:all", false},
+		{"report:view", "Report:view", false},
+
+		// "*" ครอบคลุมทุกอย่าง
+		{"*", "report:view", true},
+		{"*", "system:admin", true},
+		{"*", "*", true},
+
+		// Wildcard ชั้นสุดท้าย ครอบคลุมทุกชั้นที่อยู่ใต้ Prefix
+		{"report:*", "report:view", true},
+		{"report:*", "report:export:csv", true},
+		{"report:*", "report:*", true},
+		{"report:*", "report", false},
+		{"report:*", "reports:view", false},
+		{"report:*", "billing:view", false},
+		{"report:export:*", "report:export:csv", true},
+		{"report:export:*", "report:view", false},
+
+		// Wildcard ฝั่ง required ไม่นับ (ถือ Permission เดียวไม่ได้ครอบคลุมทั้งกลุ่ม)
+		{"report:view", "*", false},
+		{"report:view", "report:*", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.granted+" / "+tt.required, func(t *testing.T) {
+			if got := PermissionMatches(tt.granted, tt.required); got != tt.want {
+				t.Errorf("PermissionMatches(%q, %q) = %v, want %v", tt.granted, tt.required, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken เก็บเฉพาะ Hash ของ Token (ไม่เก็บตัวจริง)
+// Token ที่ถูก Rotate ต่อกันมาจะอยู่ใน Family เดียวกัน เพื่อใช้ Revoke ทั้งสายเมื่อเจอการ Reuse
+type RefreshToken struct {
+	Model
+	UserUid    uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_uid"`
+	FamilyID   uuid.UUID  `gorm:"type:uuid;index;not null" json:"family_id"`
+	TenantUid  *uuid.UUID `gorm:"type:uuid" json:"tenant_uid,omitempty"` // Tenant ที่เลือกตอน Login (ออก Token ใหม่ให้ Tenant เดิม)
+	TokenHash  string     `gorm:"uniqueIndex;not null;size:64" json:"-"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy *uuid.UUID `gorm:"type:uuid" json:"replaced_by,omitempty"`
+}
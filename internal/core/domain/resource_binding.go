@@ -0,0 +1,18 @@
+package domain
+
+import "github.com/google/uuid"
+
+// WildcardResourceID ใช้ผูก Role กับทุก Resource ของประเภทนั้น (เช่น document:*)
+const WildcardResourceID = "*"
+
+// ResourceRoleBinding ให้ Role กับ User เฉพาะบน Resource หนึ่งตัว
+// เช่น Alice เป็น editor ของ document 42 (แต่ไม่ใช่ document อื่น)
+type ResourceRoleBinding struct {
+	Model
+	UserUid      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_resource_binding" json:"user_uid"`
+	RoleUid      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_resource_binding" json:"role_uid"`
+	ResourceType string    `gorm:"size:100;not null;uniqueIndex:idx_resource_binding" json:"resource_type"`
+	ResourceID   string    `gorm:"size:255;not null;uniqueIndex:idx_resource_binding" json:"resource_id"`
+
+	Role *Role `gorm:"foreignKey:RoleUid;references:Uid" json:"role,omitempty"`
+}
@@ -2,9 +2,21 @@ package domain
 
 type Role struct {
 	Model
-	Name string `gorm:"uniqueIndex;not null;size:255" json:"name"`
+	// ชื่อห้ามซ้ำเฉพาะแถวที่ยังไม่ถูกลบ (ลบแล้วสร้างชื่อเดิมใหม่ได้ ส่วน Restore ตัวเก่าทีหลังจะได้ 409)
+	// DB เดิมต้อง DROP INDEX idx_roles_name (Unique แบบเต็มตาราง) เอง AutoMigrate ไม่ลบ Index เก่าให้
+	Name        string `gorm:"uniqueIndex:idx_roles_name_active,where:deleted_at IS NULL;not null;size:255" json:"name"`
+	Description string `gorm:"size:1000" json:"description"`
 
 	// เพิ่มความสัมพันธ์
 	Permissions []*Permission `gorm:"many2many:role_permissions;" json:"permissions"`
 	Users       []*User       `gorm:"many2many:user_roles;" json:"-"`
+
+	// PermissionLinks คือแถวของ role_permissions ตรงๆ (มี Condition ของแต่ละ Permission)
+	PermissionLinks []RolePermission `gorm:"foreignKey:RoleUid;references:Uid" json:"permission_links,omitempty"`
+
+	// Role Hierarchy: Role นี้จะได้รับสิทธิ์ทั้งหมดของ Parent (สืบทอดต่อกันเป็นทอดๆ)
+	Parents []*Role `gorm:"many2many:role_parents;joinForeignKey:RoleUid;joinReferences:ParentUid" json:"parents,omitempty"`
+
+	// Deny Rules ของ Role (ชนะ Permission ที่ได้จากทุก Role)
+	Denies []RoleDenyRule `gorm:"foreignKey:RoleUid;references:Uid" json:"denies"`
 }
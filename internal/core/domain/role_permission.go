@@ -0,0 +1,11 @@
+package domain
+
+import "github.com/google/uuid"
+
+// RolePermission คือตาราง Join role_permissions (Role <-> Permission)
+// Condition ว่าง = ให้สิทธิ์เสมอ ถ้าไม่ว่างจะให้สิทธิ์เฉพาะเมื่อเงื่อนไขเป็นจริง เช่น `time in "09:00-18:00"`
+type RolePermission struct {
+	RoleUid       uuid.UUID `gorm:"type:uuid;primaryKey" json:"role_uid"`
+	PermissionUid uuid.UUID `gorm:"type:uuid;primaryKey" json:"permission_uid"`
+	Condition     string    `gorm:"type:text;not null;default:''" json:"condition,omitempty"`
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tenant คือองค์กรลูกค้า สิทธิ์ที่ได้จาก Tenant หนึ่งใช้กับ Tenant อื่นไม่ได้
+type Tenant struct {
+	Model
+	Name string `gorm:"uniqueIndex;not null;size:255" json:"name"`
+}
+
+// TenantRoleAssignment ให้ Role กับ User เฉพาะใน Tenant หนึ่ง
+// (ต่างจาก user_roles ที่เป็น Role ระดับ Platform ใช้ได้ทุก Tenant)
+type TenantRoleAssignment struct {
+	Model
+	TenantUid uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_user_role" json:"tenant_uid"`
+	UserUid   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_user_role;index" json:"user_uid"`
+	RoleUid   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_user_role" json:"role_uid"`
+
+	// ช่วงเวลาที่ Assignment ใช้งานได้ (nil = ไม่จำกัด)
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `gorm:"index" json:"valid_until,omitempty"`
+
+	Tenant *Tenant `gorm:"foreignKey:TenantUid;references:Uid" json:"tenant,omitempty"`
+	Role   *Role   `gorm:"foreignKey:RoleUid;references:Uid" json:"role,omitempty"`
+}
+
+func (TenantRoleAssignment) TableName() string {
+	return "tenant_user_roles"
+}
+
+func (a TenantRoleAssignment) IsActiveAt(t time.Time) bool {
+	return withinWindow(a.ValidFrom, a.ValidUntil, t)
+}
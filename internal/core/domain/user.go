@@ -1,11 +1,34 @@
 package domain
 
+import "time"
+
+const (
+	UserKindHuman   = "user"
+	UserKindService = "service"
+)
+
 type User struct {
 	Model
 	Username string `gorm:"uniqueIndex;not null;size:255" json:"username"`
 	Email    string `gorm:"uniqueIndex;not null;size:255" json:"email"`
-	Password string `gorm:"size:255" json:"password"`
+	Password string `gorm:"size:255" json:"-"` // bcrypt Hash ห้ามส่งออกทาง JSON
+
+	// Kind แยก User จริงกับ Service Account (เครื่องที่เรียกด้วย API Key, Login ด้วยรหัสผ่านไม่ได้)
+	Kind        string `gorm:"size:20;not null;default:'user';index" json:"kind"`
+	Description string `gorm:"size:1000" json:"description,omitempty"`
+
+	// EmailVerifiedAt ไม่เป็น nil = ยืนยันแล้วว่าเป็นเจ้าของอีเมล (เปลี่ยนอีเมลแล้วต้องยืนยันใหม่)
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	// DisabledAt ไม่เป็น nil = บัญชีถูกระงับ (Login ไม่ได้ และ Token ที่มีอยู่ใช้ไม่ได้)
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	// MustChangePassword Admin สั่งให้เปลี่ยนรหัสผ่านก่อน Login ครั้งถัดไป
+	MustChangePassword bool `gorm:"not null;default:false" json:"must_change_password"`
 
 	// เพิ่มบรรทัดนี้: User มีได้หลาย Role
 	Roles []*Role `gorm:"many2many:user_roles;" json:"roles"`
 }
+
+func (u *User) IsServiceAccount() bool {
+	return u.Kind == UserKindService
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserRole คือตาราง Join user_roles (User <-> Role) ที่มีช่วงเวลาใช้งาน
+// ValidFrom / ValidUntil เป็น nil = ไม่จำกัด (Assignment ถาวรแบบเดิม)
+type UserRole struct {
+	UserUid    uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_uid"`
+	RoleUid    uuid.UUID  `gorm:"type:uuid;primaryKey" json:"role_uid"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `gorm:"index" json:"valid_until,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// IsActiveAt เช็คว่า Assignment ใช้งานได้ ณ เวลา t หรือไม่
+func (ur UserRole) IsActiveAt(t time.Time) bool {
+	return withinWindow(ur.ValidFrom, ur.ValidUntil, t)
+}
+
+// ValidateWindow ตรวจช่วงเวลาก่อนบันทึก Assignment
+func ValidateWindow(from *time.Time, until *time.Time, now time.Time) error {
+	if until == nil {
+		return nil
+	}
+	if !until.After(now) {
+		return fmt.Errorf("%w: valid_until is in the past", ErrInvalidValidityWindow)
+	}
+	if from != nil && !until.After(*from) {
+		return fmt.Errorf("%w: valid_until must be after valid_from", ErrInvalidValidityWindow)
+	}
+	return nil
+}
+
+// withinWindow: [from, until) โดย nil หมายถึงไม่จำกัดฝั่งนั้น
+func withinWindow(from *time.Time, until *time.Time, t time.Time) bool {
+	if from != nil && t.Before(*from) {
+		return false
+	}
+	if until != nil && !t.Before(*until) {
+		return false
+	}
+	return true
+}
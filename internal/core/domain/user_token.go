@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Purpose ของ UserToken
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+)
+
+// UserToken Token ใช้ครั้งเดียวที่ส่งไปทางอีเมล เก็บแค่ Hash (เหมือน RefreshToken)
+type UserToken struct {
+	Model
+	UserUid   uuid.UUID `gorm:"type:uuid;index;not null" json:"user_uid"`
+	Purpose   string    `gorm:"size:32;not null;index" json:"purpose"`
+	TokenHash string    `gorm:"uniqueIndex;not null;size:64" json:"-"`
+	// Email ที่ส่ง Token ไป: User เปลี่ยนอีเมลหลังจากนั้น Token นี้ใช้ไม่ได้
+	Email     string     `gorm:"size:255;not null" json:"email"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+// Usable ยังไม่ถูกใช้และยังไม่หมดอายุ ณ เวลา now
+func (t *UserToken) Usable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ValidationError รวม Error ของหลาย Field (Handler ตอบเป็น 422 พร้อมข้อความแยกตาม Field)
+// ใช้ errors.Is(err, ErrValidation) ได้
+type ValidationError struct {
+	Fields map[string][]string
+}
+
+func (e *ValidationError) Add(field string, message string) {
+	if e.Fields == nil {
+		e.Fields = make(map[string][]string)
+	}
+	e.Fields[field] = append(e.Fields[field], message)
+}
+
+// OrNil คืน nil ถ้าไม่มี Field ไหนผิด (ใช้ตอน return จะได้ไม่ได้ error ที่ไม่ใช่ nil แต่ว่างเปล่า)
+func (e *ValidationError) OrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for f := range e.Fields {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		parts = append(parts, fmt.Sprintf("%s: %s", f, strings.Join(e.Fields[f], ", ")))
+	}
+	return fmt.Sprintf("%s (%s)", ErrValidation, strings.Join(parts, "; "))
+}
+
+func (e *ValidationError) Unwrap() error { return ErrValidation }
+
+// usernamePattern: 3-32 ตัว ขึ้นต้นด้วยตัวอักษรหรือตัวเลข ตามด้วย . _ - ได้
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{2,31}$`)
+
+// ValidateUsername คืนข้อความ Error (ว่าง = ผ่าน)
+func ValidateUsername(username string) string {
+	if !usernamePattern.MatchString(username) {
+		return "must be 3-32 characters of letters, digits, '.', '_' or '-' and start with a letter or digit"
+	}
+	return ""
+}
+
+// ValidateEmail รับเฉพาะที่อยู่ล้วนๆ (ไม่รับ "Name <a@b.com>")
+func ValidateEmail(email string) string {
+	if len(email) > 255 {
+		return "must be at most 255 characters"
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@"):], ".") {
+		return "must be a valid email address"
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package port
+
+import (
+	"context"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+)
+
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *domain.UserToken) error
+	GetByHash(ctx context.Context, purpose string, hash string) (*domain.UserToken, error)
+	// MarkUsed ตั้ง used_at แบบมีเงื่อนไข คืน domain.ErrInvalidUserToken ถ้ามี Request อื่นใช้ไปก่อนแล้ว
+	MarkUsed(ctx context.Context, uid string) error
+	// InvalidateForUser ยกเลิก Token ที่ยังไม่ได้ใช้ของ purpose นั้น (ขอใหม่แล้วลิงก์เก่าใช้ไม่ได้)
+	InvalidateForUser(ctx context.Context, userID string, purpose string) error
+}
+
+// Notifier ส่งข้อความถึง User (SMTP หรือ Log ตอน Dev) ต้อง Implement ให้ปลอดภัยต่อการเรียกพร้อมกัน
+type Notifier interface {
+	Send(ctx context.Context, msg *Notification) error
+}
+
+type Notification struct {
+	To      string
+	Subject string
+	Body    string // Plain Text
+}
+
+// AccountTokenService ลืมรหัสผ่าน / ยืนยันอีเมล ผ่าน Token ใช้ครั้งเดียวที่ส่งทาง Notifier
+type AccountTokenService interface {
+	// RequestPasswordReset ไม่บอกว่าอีเมลมีในระบบหรือไม่ (คืน nil เสมอถ้า Input ถูกต้อง)
+	RequestPasswordReset(ctx context.Context, req *PasswordResetRequestReq) error
+	// ResetPassword ตั้งรหัสผ่านใหม่ แล้วเพิกถอนทุก Session และปลดล็อก Login
+	ResetPassword(ctx context.Context, req *PasswordResetReq) error
+
+	RequestEmailVerification(ctx context.Context, userID string) error
+	VerifyEmail(ctx context.Context, req *VerifyEmailReq) error
+}
+
+// --- DTOs ---
+
+type PasswordResetRequestReq struct {
+	Email string `json:"email"`
+	IP    string `json:"-"`
+}
+
+type PasswordResetReq struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type VerifyEmailReq struct {
+	Token string `json:"token"`
+}
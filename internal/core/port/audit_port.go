@@ -0,0 +1,146 @@
+package port
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+)
+
+type AuditRepository interface {
+	// Append ตั้ง Seq / PrevHash / Hash แล้วบันทึก (Lock ทั้ง Chain ให้ต่อท้ายได้ทีละรายการ ทุก Instance)
+	Append(ctx context.Context, event *domain.AuditEvent) error
+	List(ctx context.Context, filter AuditFilter) ([]domain.AuditEvent, int64, error)
+	// ListAfter คืน Event ที่ Seq > afterSeq เรียงตาม Seq (ใช้ไล่ตรวจ Chain ทีละชุด)
+	ListAfter(ctx context.Context, afterSeq int64, limit int) ([]domain.AuditEvent, error)
+}
+
+// AuditLogger ใช้ใน Service อื่นๆ: Actor / IP / Request ID / Tenant มาจาก ctx (WithRequestMeta, WithTenant)
+type AuditLogger interface {
+	// Record คืน domain.ErrAuditWriteFailed ถ้าเขียนไม่สำเร็จ (Log ไว้แล้ว)
+	// งานแก้ไขสิทธิ์ / บัญชีต้องเรียกภายใน Transactor.WithinTx เดียวกับการแก้ไข แล้วคืน Error นี้ออกไป
+	// (เขียน Audit ไม่ได้ = การแก้ไขถูก Rollback ด้วย) ส่วน Auth Event (Login ฯลฯ) Log ไว้อย่างเดียวพอ
+	Record(ctx context.Context, entry AuditEntry) error
+	// RecordDenied สุ่มเก็บการปฏิเสธสิทธิ์ตาม audit.denied_sample_rate (0 = ไม่เก็บ)
+	// ไม่รอ DB: เข้าคิวให้เขียนใน Background ถ้าคิวเต็มจะทิ้ง (นับจำนวนที่ทิ้งไว้ใน Log)
+	RecordDenied(ctx context.Context, userID string, perm string, resource *Resource, reason string)
+}
+
+// AuditService ค้นหา / ตรวจ Chain (Admin)
+type AuditService interface {
+	AuditLogger
+
+	Query(ctx context.Context, query *AuditQuery) ([]domain.AuditEvent, int64, error)
+	// Verify ไล่คำนวณ Hash ใหม่ทั้ง Chain คืนตำแหน่งแรกที่ไม่ตรง
+	Verify(ctx context.Context) (*AuditVerification, error)
+}
+
+// AuditEntry: Before / After เป็นค่าอะไรก็ได้ที่ Marshal เป็น JSON ได้ (nil = ไม่มี)
+type AuditEntry struct {
+	Action     string
+	TargetType string
+	TargetID   string
+	Outcome    string // ว่าง = domain.AuditSuccess
+	Before     any
+	After      any
+	// ActorID ใช้แทน Actor จาก ctx (เช่น Login ที่ยังไม่มี Actor)
+	ActorID string
+}
+
+// Target Type ที่ใช้ใน Audit
+const (
+	AuditTargetRole           = "role"
+	AuditTargetPermission     = "permission"
+	AuditTargetUser           = "user"
+	AuditTargetTenant         = "tenant"
+	AuditTargetServiceAccount = "service_account"
+	AuditTargetAPIKey         = "api_key"
+)
+
+// --- Request Meta ใน Context ---
+// Middleware สร้างไว้ตั้งแต่ต้น Request แล้วเติม Actor หลังยืนยันตัวตน (จึงเป็น Pointer)
+
+type RequestMeta struct {
+	RequestID string
+	IP        string
+	ActorID   string
+	ActorType string // domain.AuditActor*
+}
+
+type requestMetaCtxKey struct{}
+
+func WithRequestMeta(ctx context.Context, meta *RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaCtxKey{}, meta)
+}
+
+// RequestMetaFromContext คืน nil ถ้าไม่ได้มาจาก HTTP Request (เช่น Background Job)
+func RequestMetaFromContext(ctx context.Context) *RequestMeta {
+	meta, _ := ctx.Value(requestMetaCtxKey{}).(*RequestMeta)
+	return meta
+}
+
+// --- Query ---
+
+type AuditQuery struct {
+	ActorID    string
+	Action     string // ลงท้ายด้วย "*" = ขึ้นต้นด้วย (เช่น "role.*")
+	TargetType string
+	TargetID   string
+	Outcome    string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	Page       int
+	PageSize   int
+}
+
+type AuditFilter struct {
+	ActorID      string
+	Action       string
+	ActionPrefix string
+	TargetType   string
+	TargetID     string
+	Outcome      string
+	RequestID    string
+	From         *time.Time
+	To           *time.Time
+	Limit        int
+	Offset       int
+}
+
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Checked  int64  `json:"checked"`
+	HeadSeq  int64  `json:"head_seq"`
+	HeadHash string `json:"head_hash"` // เก็บไว้นอกระบบเพื่อเทียบทีหลัง (ลบรายการท้าย Chain จะรู้ได้จากตรงนี้)
+	// BrokenAtSeq / Reason มีค่าเมื่อ Valid = false
+	BrokenAtSeq int64  `json:"broken_at_seq,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// AuditEventResponse: Before / After ส่งออกเป็น JSON Object (ไม่ใช่ String)
+type AuditEventResponse struct {
+	Seq        int64           `json:"seq"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	ActorID    string          `json:"actor_id,omitempty"`
+	ActorType  string          `json:"actor_type"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type,omitempty"`
+	TargetID   string          `json:"target_id,omitempty"`
+	Outcome    string          `json:"outcome"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	TenantID   string          `json:"tenant_id,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+type AuditPage struct {
+	Events   []AuditEventResponse `json:"events"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"page_size"`
+	Total    int64                `json:"total"`
+}
@@ -2,14 +2,69 @@ package port
 
 import (
 	"context"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
 )
 
 // Service Port (Use Case)
 type AuthService interface {
 	Register(ctx context.Context, req *RegisterReq) error
 	Login(ctx context.Context, req *LoginReq) (*AuthResponse, error)
+	Refresh(ctx context.Context, req *RefreshReq) (*AuthResponse, error)
+	Logout(ctx context.Context, req *LogoutReq) error
+
+	// --- Session Revocation ---
+	RevokeAllSessions(ctx context.Context, userID string) error
+	IsTokenRevoked(ctx context.Context, userID string, tokenID string, issuedAt time.Time) (bool, error)
+	IsUserDisabled(ctx context.Context, userID string) (bool, error)
+
+	// ChangePassword ไม่ต้อง Login (ใช้ได้ตอนโดนบังคับเปลี่ยนรหัส) เปลี่ยนแล้วเพิกถอนทุก Session
+	ChangePassword(ctx context.Context, req *ChangePasswordReq) error
+
+	// UnlockAccount (Admin) ล้าง Counter การ Login ผิดของ User
+	UnlockAccount(ctx context.Context, userID string) error
+
+	// --- MFA (TOTP) ---
+	// VerifyMFA ขั้นที่ 2 ของ Login: แลก Challenge Token + Code เป็น Token จริง
+	VerifyMFA(ctx context.Context, req *MFAVerifyReq) (*AuthResponse, error)
+	// BeginChallengeEnrollment / ConfirmChallengeEnrollment ใช้ตอน Login แล้วโดนบังคับให้ลงทะเบียน MFA
+	BeginChallengeEnrollment(ctx context.Context, challengeToken string) (*MFAEnrollment, error)
+	ConfirmChallengeEnrollment(ctx context.Context, req *MFAVerifyReq) (*MFAEnrollResult, error)
+
+	// สำหรับ User ที่ Login แล้ว (ยืนยันแล้วจะเพิกถอนทุก Session ต้อง Login ใหม่ด้วย MFA)
+	BeginMFAEnrollment(ctx context.Context, userID string) (*MFAEnrollment, error)
+	ConfirmMFAEnrollment(ctx context.Context, userID string, code string) (*MFARecoveryCodes, error)
+	DisableMFA(ctx context.Context, userID string, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID string, code string) (*MFARecoveryCodes, error)
+}
+
+// PasswordPolicy ตรวจรหัสผ่านใหม่ คืนข้อความที่ไม่ผ่าน (ว่าง = ผ่าน)
+type PasswordPolicy interface {
+	Validate(password string, username string) []string
+}
+
+// LoginAttemptStore เก็บ Counter การ Login ผิด (Key คือ "user:<username>" หรือ "ip:<ip>")
+// และใช้นับจำนวนครั้งที่ขอส่งอีเมล Reset / Verify ด้วย (Key ขึ้นต้นด้วย "mail:")
+// Counter ที่ยังไม่หมดอายุและถึงเพดานแล้ว = ถูกล็อกอยู่
+type LoginAttemptStore interface {
+	// Failures คืนจำนวนครั้งที่ผิด และเวลาที่เหลือก่อน Counter หมดอายุ
+	Failures(ctx context.Context, key string) (int64, time.Duration, error)
+	// RecordFailure เพิ่ม Counter (ครั้งแรกตั้งอายุเป็น window) แล้วคืนค่าใหม่
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error)
+	// Lock ยืดอายุ Counter ออกไปอีก d (ใช้ตอนถึงเพดาน)
+	Lock(ctx context.Context, key string, d time.Duration) error
+	Reset(ctx context.Context, keys ...string) error
 }
 
+// LoginLockedError ใช้ errors.Is กับ domain.ErrTooManyLoginAttempts ได้ และบอกว่าต้องรออีกนานเท่าไหร่
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string { return domain.ErrTooManyLoginAttempts.Error() }
+func (e *LoginLockedError) Unwrap() error { return domain.ErrTooManyLoginAttempts }
+
 // --- DTOs (Request/Response) ---
 type RegisterReq struct {
 	Username string `json:"username"`
@@ -20,8 +75,38 @@ type RegisterReq struct {
 type LoginReq struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	TenantID string `json:"tenant_id,omitempty"` // (Optional) ผูก Token กับ Tenant
+	IP       string `json:"-"`                   // Handler ใส่ให้ (ใช้นับ Login ผิดต่อ IP)
+}
+
+type ChangePasswordReq struct {
+	Username        string `json:"username"`
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+	IP              string `json:"-"`
 }
 
+type RefreshReq struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutReq: ข้อมูลของ Access Token ปัจจุบันมาจาก Middleware, RefreshToken (ถ้ามี) มาจาก Body
+type LogoutReq struct {
+	UserID       string    `json:"-"`
+	TokenID      string    `json:"-"`
+	ExpiresAt    time.Time `json:"-"`
+	RefreshToken string    `json:"refresh_token"`
+}
+
+// AuthResponse: ถ้า MFARequired = true จะยังไม่มี Token ต้องส่ง ChallengeToken ไปยืนยัน MFA ก่อน
 type AuthResponse struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"` // อายุ Access Token (วินาที)
+
+	MFARequired bool `json:"mfa_required,omitempty"`
+	// MFAEnrollmentRequired ต้องลงทะเบียน MFA ก่อน (ถือ Role / Permission ที่บังคับ แต่ยังไม่เปิด)
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+	ChallengeToken        string `json:"challenge_token,omitempty"`
 }
@@ -0,0 +1,69 @@
+package port
+
+import "context"
+
+// AuthzService คือ Policy Decision Point ให้ Service อื่นถามสิทธิ์ของ User ได้โดยตรง
+// (ไม่ต้องยิงผ่าน Route ที่มี guard)
+type AuthzService interface {
+	Check(ctx context.Context, req *AuthzCheckReq) (*AuthzResult, error)
+	CheckBatch(ctx context.Context, req *AuthzBatchReq) (*AuthzBatchResult, error)
+}
+
+// ClientAuthenticator ตรวจ Credential ของ Service ที่เรียก Decision API (แยกจาก User)
+type ClientAuthenticator interface {
+	Authenticate(clientID string, secret string) error
+}
+
+// AuthzSubject ระบุ User ที่ต้องการถาม: ใส่ user_id หรือ token (Access Token ของ User) อย่างใดอย่างหนึ่ง
+type AuthzSubject struct {
+	UserID   string `json:"user_id,omitempty"`
+	Token    string `json:"token,omitempty"`
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+// AuthzContext ข้อมูลของ Request ต้นทาง สำหรับ Conditional Grant (เวลาใช้เวลาปัจจุบันเสมอ)
+type AuthzContext struct {
+	IP            string `json:"ip,omitempty"`
+	ResourceOwner string `json:"resource_owner,omitempty"`
+}
+
+type AuthzCheckReq struct {
+	Subject    AuthzSubject `json:"subject"`
+	Permission string       `json:"permission"`
+	Resource   *Resource    `json:"resource,omitempty"`
+	Context    AuthzContext `json:"context"`
+}
+
+type AuthzCheckItem struct {
+	Permission string    `json:"permission"`
+	Resource   *Resource `json:"resource,omitempty"`
+}
+
+// AuthzBatchReq Subject เดียว หลาย Permission / Resource
+type AuthzBatchReq struct {
+	Subject AuthzSubject     `json:"subject"`
+	Checks  []AuthzCheckItem `json:"checks"`
+	Context AuthzContext     `json:"context"`
+}
+
+// AuthzResult ผลต่อรายการ (Reason เป็น Reason* ของ Decision หรือ AuthzReason* ด้านล่าง)
+type AuthzResult struct {
+	Permission string    `json:"permission"`
+	Resource   *Resource `json:"resource,omitempty"`
+	Allowed    bool      `json:"allowed"`
+	Reason     string    `json:"reason"`
+	Detail     string    `json:"detail,omitempty"`
+}
+
+type AuthzBatchResult struct {
+	Results []AuthzResult `json:"results"`
+}
+
+// เหตุผลที่ปฏิเสธก่อนถึงขั้นเช็ค Policy
+const (
+	AuthzReasonInvalidSubject    = "invalid_subject"
+	AuthzReasonInvalidPermission = "invalid_permission"
+	AuthzReasonTenantMismatch    = "tenant_mismatch"
+	AuthzReasonNotTenantMember   = "not_tenant_member"
+	AuthzReasonUserDisabled      = "user_disabled" // ถูกระงับหรือถูกลบ
+)
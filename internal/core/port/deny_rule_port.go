@@ -0,0 +1,17 @@
+package port
+
+import (
+	"context"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+)
+
+type DenyRuleRepository interface {
+	AddRoleDeny(ctx context.Context, rule *domain.RoleDenyRule) error
+	RemoveRoleDeny(ctx context.Context, roleID string, permission string) error
+
+	AddUserDeny(ctx context.Context, rule *domain.UserDenyRule) error
+	RemoveUserDeny(ctx context.Context, userID string, permission string) error
+	GetUserDenies(ctx context.Context, userID string) ([]domain.UserDenyRule, error)
+	GetAllUserDenies(ctx context.Context) ([]domain.UserDenyRule, error)
+}
@@ -0,0 +1,34 @@
+package port
+
+import "crypto"
+
+// KeySet เก็บกุญแจสำหรับเซ็น/ตรวจ JWT แบบ Asymmetric
+// มีได้หลายกุญแจพร้อมกัน (เพื่อ Rotate) แต่ใช้เซ็นแค่กุญแจ Active ตัวเดียว
+type KeySet interface {
+	// SigningKey คืนกุญแจ Active สำหรับเซ็น Token ใหม่
+	SigningKey() (kid string, alg string, key crypto.Signer)
+	// VerificationKey หา Public Key ตาม kid ใน Header ของ Token
+	VerificationKey(kid string) (alg string, key crypto.PublicKey, ok bool)
+	// JWKS คืน Public Key ทั้งหมดในรูปแบบ JSON Web Key Set
+	JWKS() *JWKSet
+}
+
+// --- DTOs: JSON Web Key Set (RFC 7517) ---
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// OKP (Ed25519)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
@@ -0,0 +1,56 @@
+package port
+
+import (
+	"context"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+)
+
+type MFARepository interface {
+	// GetCredential คืน domain.ErrNotFound ถ้ายังไม่เคยลงทะเบียน
+	GetCredential(ctx context.Context, userID string) (*domain.MFACredential, error)
+	// SaveCredential เริ่มลงทะเบียนใหม่ (ทับ Secret เดิมที่ยังไม่ยืนยัน)
+	SaveCredential(ctx context.Context, cred *domain.MFACredential) error
+	ConfirmCredential(ctx context.Context, userID string) error
+	// DeleteCredential ลบ Secret พร้อม Recovery Code ทั้งหมด
+	DeleteCredential(ctx context.Context, userID string) error
+
+	// MarkStepUsed บันทึก Time Step ที่ใช้แล้ว คืน false ถ้า Step นี้ (หรือใหม่กว่า) ถูกใช้ไปก่อนแล้ว
+	MarkStepUsed(ctx context.Context, userID string, step int64) (bool, error)
+
+	// ReplaceRecoveryCodes ลบชุดเดิมทิ้งแล้วใส่ชุดใหม่
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	// UseRecoveryCode คืน false ถ้าไม่มี Code นี้ หรือใช้ไปแล้ว
+	UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error)
+}
+
+// --- DTOs ---
+
+// MFAEnrollment ให้ User สแกน QR จาก URI (หรือพิมพ์ Secret เอง) แล้วส่ง Code แรกมายืนยัน
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// MFAVerifyReq ขั้นที่ 2 ของ Login: ส่ง Code จาก App หรือ Recovery Code อย่างใดอย่างหนึ่ง
+type MFAVerifyReq struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+	IP             string `json:"-"`
+}
+
+// MFACodeReq ใช้กับ Endpoint ของ User ที่ Login แล้ว (ยืนยัน / ปิด MFA / ขอ Recovery Code ใหม่)
+type MFACodeReq struct {
+	Code string `json:"code"`
+}
+
+type MFARecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAEnrollResult ยืนยันการลงทะเบียนระหว่าง Login: ได้ทั้ง Recovery Code และ Token
+type MFAEnrollResult struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	*AuthResponse
+}
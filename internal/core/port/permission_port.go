@@ -10,4 +10,10 @@ type PermissionRepository interface {
 	Create(ctx context.Context, perm *domain.Permission) error
 	GetAll(ctx context.Context) ([]domain.Permission, error)
 	GetPermissionByName(ctx context.Context, name string) (*domain.Permission, error)
+	GetPermissionByUID(ctx context.Context, uid string) (*domain.Permission, error)
+	Update(ctx context.Context, perm *domain.Permission) error
+	// Delete / Restore ใช้กฎเดียวกับ RoleRepository (cascade = ลบ role_permissions ทิ้งถาวร)
+	Delete(ctx context.Context, uid string, cascade bool) error
+	Restore(ctx context.Context, uid string) error
+	GetDeleted(ctx context.Context) ([]domain.Permission, error)
 }
@@ -1,40 +1,283 @@
 package port
 
-import "github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+import (
+	"context"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+)
 
 type RBACService interface {
 	LoadPolicy() error
-	CheckAccess(userID string, requiredPerm string) (bool, error)
+	// SyncPolicy โหลด Policy ใหม่ถ้า Version กลาง (ที่ทุก Instance เห็น) ไม่ตรงกับที่โหลดไว้ คืน true ถ้าโหลดใหม่
+	SyncPolicy(ctx context.Context) (bool, error)
+	// CheckAccess ถ้า ctx มี Tenant (WithTenant) จะรวม Role ของ Tenant นั้นด้วย
+	CheckAccess(ctx context.Context, userID string, requiredPerm string) (bool, error)
+	// CheckAccessOn เช็คสิทธิ์บน Resource ตัวใดตัวหนึ่ง (Role ทั่วไป + Role ที่ผูกกับ Resource นั้น)
+	CheckAccessOn(ctx context.Context, userID string, requiredPerm string, resource Resource) (bool, error)
+	// Decide เหมือน CheckAccess / CheckAccessOn แต่คืนเหตุผลด้วย (resource nil = ไม่เจาะจง Resource)
+	Decide(ctx context.Context, userID string, requiredPerm string, resource *Resource) (Decision, error)
+	// Explain ตัดสินแบบเดียวกับ Decide แล้วคืนเส้นทางทั้งหมดที่ใช้ตัดสิน (สำหรับ Support / Debug)
+	Explain(ctx context.Context, userID string, requiredPerm string, resource *Resource) (*Explanation, error)
 
 	// --- CRUD Methods ---
-	CreateRole(req *CreateRoleReq) error
-	CreatePermission(req *CreatePermReq) error
-	AssignPermissionToRole(req *AssignPermReq) error
-	AssignRoleToUser(req *AssignRoleReq) error
-	RemovePermissionFromRole(req *UnassignPermReq) error
-	RemoveRoleFromUser(req *UnassignRoleReq) error
+	CreateRole(ctx context.Context, req *CreateRoleReq) error
+	CreatePermission(ctx context.Context, req *CreatePermReq) error
+	// UpdateRole / UpdatePermission คืนค่าที่บันทึกแล้วคู่กับ Error ได้ (ลง DB แล้ว แต่ Reload Policy / ลบ Cache ไม่สำเร็จ)
+	UpdateRole(ctx context.Context, roleID string, req *UpdateRoleReq) (*domain.Role, error)
+	DeleteRole(ctx context.Context, roleID string, cascade bool) error
+	// Restore คืน ErrAlreadyExists ถ้ามีชื่อเดียวกันที่สร้างใหม่หลังลบไปแล้ว
+	RestoreRole(ctx context.Context, roleID string) error
+	GetDeletedRoles(ctx context.Context) ([]domain.Role, error)
+	UpdatePermission(ctx context.Context, permID string, req *UpdatePermReq) (*domain.Permission, error)
+	DeletePermission(ctx context.Context, permID string, cascade bool) error
+	RestorePermission(ctx context.Context, permID string) error
+	GetDeletedPermissions(ctx context.Context) ([]domain.Permission, error)
+	AssignPermissionToRole(ctx context.Context, req *AssignPermReq) error
+	AssignRoleToUser(ctx context.Context, req *AssignRoleReq) error
+	RemovePermissionFromRole(ctx context.Context, req *UnassignPermReq) error
+	RemoveRoleFromUser(ctx context.Context, req *UnassignRoleReq) error
+	// SweepExpiredAssignments ลบ Assignment ที่หมดอายุแล้ว คืนจำนวนที่ลบ
+	SweepExpiredAssignments(ctx context.Context) (int, error)
+	AssignParentToRole(ctx context.Context, req *AssignParentReq) error
+	RemoveParentFromRole(ctx context.Context, req *UnassignParentReq) error
 
 	GetAllRoles() ([]domain.Role, error)
 	GetAllPermissions() ([]domain.Permission, error)
+	GetAllPermissionsExpanded() ([]ExpandedPermission, error)
 	GetUserRoles(userID string) ([]domain.Role, error)
+	// GetEffectivePermissions สิทธิ์ทั้งหมดของ User (รวม Parent, หัก Deny) คำนวณจาก Policy ใน Memory
+	// ถ้า ctx มี Tenant จะรวม Role ของ Tenant นั้นด้วย (ไม่รวม Role ที่ผูกกับ Resource)
+	GetEffectivePermissions(ctx context.Context, userID string) (*EffectivePermissions, error)
+	// GetUserRoleNames ชื่อ Role ที่ User ถือ รวม Parent ทุกชั้น (Tenant ใน ctx เหมือน GetEffectivePermissions)
+	GetUserRoleNames(ctx context.Context, userID string) ([]string, error)
+	// GetPermissionHolders (Who Can) Role และ User ที่ถือ Permission นี้อยู่ตอนนี้ (รวม Parent / Wildcard, หัก Deny)
+	GetPermissionHolders(ctx context.Context, query *HoldersQuery) (*PermissionHolders, error)
+
+	// --- Resource-scoped Role Bindings ---
+	BindRoleOnResource(ctx context.Context, req *BindResourceRoleReq) error
+	UnbindRoleOnResource(ctx context.Context, req *UnbindResourceRoleReq) error
+	GetUserBindings(userID string) ([]domain.ResourceRoleBinding, error)
+
+	// --- Deny Rules (Deny ชนะ Allow) ---
+	AddRoleDeny(ctx context.Context, req *RoleDenyReq) error
+	RemoveRoleDeny(ctx context.Context, req *RoleDenyReq) error
+	AddUserDeny(ctx context.Context, req *UserDenyReq) error
+	RemoveUserDeny(ctx context.Context, req *UserDenyReq) error
+	GetUserDenies(userID string) ([]domain.UserDenyRule, error)
+
+	// --- Tenant ---
+	CreateTenant(ctx context.Context, req *CreateTenantReq) error
+	GetAllTenants() ([]domain.Tenant, error)
+	AssignTenantRole(ctx context.Context, req *AssignTenantRoleReq) error
+	RemoveTenantRole(ctx context.Context, req *UnassignTenantRoleReq) error
+	GetUserTenantRoles(userID string) ([]domain.TenantRoleAssignment, error)
+	IsTenantMember(ctx context.Context, tenantID string, userID string) (bool, error)
+}
+
+// Resource ที่ต้องการเช็คสิทธิ์ เช่น {Type: "document", ID: "42"}
+type Resource struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Decision ผลการตัดสินสิทธิ์พร้อมเหตุผล (Reason* ด้านล่าง)
+type Decision struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+const (
+	ReasonGranted            = "granted"
+	ReasonGrantedByCondition = "granted_by_condition"
+	ReasonDeniedByUserRule   = "denied_by_user_rule"
+	ReasonDeniedByRoleRule   = "denied_by_role_rule"
+	ReasonNoMatchingGrant    = "no_matching_grant"
+)
+
+// --- Effective Permissions ---
+
+// PermissionSource Role ที่ทำให้ได้ Permission นี้ (Path เริ่มจาก Role ที่ User ถือ)
+type PermissionSource struct {
+	Role      string   `json:"role"`
+	Path      []string `json:"path"`
+	Condition string   `json:"condition,omitempty"` // ได้เฉพาะเมื่อเงื่อนไขเป็นจริง
+}
+
+// EffectivePermission: Except คือ Deny ที่แคบกว่า (เช่น ได้ "report:*" แต่โดน Deny "report:delete")
+type EffectivePermission struct {
+	Permission string             `json:"permission"`
+	Sources    []PermissionSource `json:"sources"`
+	Except     []string           `json:"except,omitempty"`
+}
+
+type EffectivePermissions struct {
+	UserID        string                `json:"user_id"`
+	TenantID      string                `json:"tenant_id,omitempty"`
+	PolicyVersion uint64                `json:"policy_version"`
+	Permissions   []EffectivePermission `json:"permissions"`
+}
+
+// --- Who Can ---
+
+// HoldersQuery: PageSize 0 = คืนทั้งหมด (ใช้ตอน Export)
+type HoldersQuery struct {
+	Permission string
+	Search     string // username / email
+	Role       string // เฉพาะ User ที่ได้สิทธิ์ผ่าน Role นี้
+	Scope      string // RoleOrigin*
+	TenantID   string
+	Page       int
+	PageSize   int
+}
+
+// HoldingRole Role ที่ได้ Permission (Path ถึง Role ที่มี Permission จริง)
+type HoldingRole struct {
+	Role       string   `json:"role"`
+	Path       []string `json:"path"`
+	Permission string   `json:"permission"`
+	Condition  string   `json:"condition,omitempty"`
+}
+
+type HolderGrant struct {
+	Role      string    `json:"role"`
+	Scope     string    `json:"scope"`
+	TenantID  string    `json:"tenant_id,omitempty"`
+	Resource  *Resource `json:"resource,omitempty"`
+	Path      []string  `json:"path"`
+	Condition string    `json:"condition,omitempty"`
+}
+
+type PermissionHolder struct {
+	UserID   string        `json:"user_id"`
+	Username string        `json:"username"`
+	Email    string        `json:"email"`
+	Grants   []HolderGrant `json:"grants"`
+}
+
+type PermissionHolders struct {
+	Permission    string             `json:"permission"`
+	PolicyVersion uint64             `json:"policy_version"`
+	Roles         []HoldingRole      `json:"roles"`
+	Users         []PermissionHolder `json:"users"`
+	Page          int                `json:"page"`
+	PageSize      int                `json:"page_size"`
+	Total         int                `json:"total"`
+}
+
+// --- Explain ---
+
+// ที่มาของ Role ใน ExplainedRole
+const (
+	RoleOriginPlatform = "platform" // user_roles
+	RoleOriginTenant   = "tenant"   // tenant_user_roles
+	RoleOriginBinding  = "binding"  // resource_role_bindings
+
+	RoleSourceCache = "cache"
+	RoleSourceDB    = "db"
+)
+
+type ExplainedRole struct {
+	Name   string `json:"name"`
+	Origin string `json:"origin"`
+	Source string `json:"source"` // cache | db
+}
+
+// GrantPath เส้นทางจาก Role ที่ User ถือ ไปจนถึง Role ที่มี Permission ตรงกับที่ขอ
+type GrantPath struct {
+	Role         string   `json:"role"`
+	Path         []string `json:"path"` // [Role ที่ถือ, Parent, ..., Role ที่มี Permission]
+	Permission   string   `json:"permission"`
+	Condition    string   `json:"condition,omitempty"`
+	ConditionMet *bool    `json:"condition_met,omitempty"`
+}
+
+type MatchedDeny struct {
+	Pattern string   `json:"pattern"`
+	Scope   string   `json:"scope"` // user | role
+	Role    string   `json:"role,omitempty"`
+	Path    []string `json:"path,omitempty"`
+}
+
+type Explanation struct {
+	UserID     string    `json:"user_id"`
+	Permission string    `json:"permission"`
+	Resource   *Resource `json:"resource,omitempty"`
+	TenantID   string    `json:"tenant_id,omitempty"`
+	Decision
+
+	PolicyVersion  uint64    `json:"policy_version"`
+	PolicyLoadedAt time.Time `json:"policy_loaded_at"`
+
+	Roles   []ExplainedRole `json:"roles"`
+	Denies  []MatchedDeny   `json:"denies"`
+	Grants  []GrantPath     `json:"grants"`
+	Summary string          `json:"summary"`
+}
+
+// AccessAttributes ข้อมูลของ Request ที่ใช้ประเมิน Condition บน Role-Permission
+// Middleware ดึงจาก Fiber Context แล้วส่งมากับ ctx (port.WithAttributes)
+type AccessAttributes struct {
+	IP            string
+	Time          time.Time
+	ResourceOwner string // uid ของเจ้าของ Resource (ว่าง = ไม่ทราบ)
+	ResourceType  string
+	ResourceID    string
+}
+
+type attributesCtxKey struct{}
+
+func WithAttributes(ctx context.Context, attrs AccessAttributes) context.Context {
+	return context.WithValue(ctx, attributesCtxKey{}, attrs)
+}
+
+// AttributesFromContext ctx ที่ไม่มี Attributes = ค่าว่างทั้งหมด (Condition ที่ต้องใช้ค่าเหล่านี้จะไม่ผ่าน)
+func AttributesFromContext(ctx context.Context) AccessAttributes {
+	attrs, _ := ctx.Value(attributesCtxKey{}).(AccessAttributes)
+	return attrs
 }
 
 type CreateRoleReq struct {
-	Name string `json:"name"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
 }
 
+// Name: "resource:action" หรือ Wildcard เช่น "report:*", "*"
 type CreatePermReq struct {
-	Name string `json:"name"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// UpdateRoleReq / UpdatePermReq: Field ที่เป็น nil = ไม่เปลี่ยน
+type UpdateRoleReq struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+type UpdatePermReq struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
 }
 
+// ExpandedPermission: Covers คือ Permission จริงที่ Wildcard นี้ครอบคลุม (nil ถ้าไม่ใช่ Wildcard)
+type ExpandedPermission struct {
+	domain.Permission
+	Covers []string `json:"covers,omitempty"`
+}
+
+// Condition (Optional) ให้สิทธิ์เฉพาะเมื่อเงื่อนไขเป็นจริง เช่น `ip in "10.0.0.0/8" && time in "09:00-18:00"`
 type AssignPermReq struct {
-	RoleName string `json:"role_name"`
-	PermName string `json:"perm_name"`
+	RoleName  string `json:"role_name"`
+	PermName  string `json:"perm_name"`
+	Condition string `json:"condition,omitempty"`
 }
 
+// ValidFrom / ValidUntil (Optional) จำกัดช่วงเวลาที่ Role ใช้งานได้ (RFC 3339)
 type AssignRoleReq struct {
-	UserID   string `json:"user_id"`
-	RoleName string `json:"role_name"`
+	UserID     string     `json:"user_id"`
+	RoleName   string     `json:"role_name"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
 }
 
 type UnassignPermReq struct {
@@ -46,3 +289,58 @@ type UnassignRoleReq struct {
 	UserID   string `json:"user_id"`
 	RoleName string `json:"role_name"`
 }
+
+// RoleName จะได้รับสิทธิ์ทั้งหมดของ ParentName
+type AssignParentReq struct {
+	RoleName   string `json:"role_name"`
+	ParentName string `json:"parent_name"`
+}
+
+type UnassignParentReq struct {
+	RoleName   string `json:"role_name"`
+	ParentName string `json:"parent_name"`
+}
+
+// ResourceID = "*" หมายถึงทุก Resource ของ ResourceType นั้น
+type BindResourceRoleReq struct {
+	UserID       string `json:"user_id"`
+	RoleName     string `json:"role_name"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+}
+
+type UnbindResourceRoleReq struct {
+	UserID       string `json:"user_id"`
+	RoleName     string `json:"role_name"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+}
+
+type CreateTenantReq struct {
+	Name string `json:"name"`
+}
+
+type AssignTenantRoleReq struct {
+	TenantID   string     `json:"tenant_id"`
+	UserID     string     `json:"user_id"`
+	RoleName   string     `json:"role_name"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+}
+
+type UnassignTenantRoleReq struct {
+	TenantID string `json:"tenant_id"`
+	UserID   string `json:"user_id"`
+	RoleName string `json:"role_name"`
+}
+
+// PermName ใช้ Wildcard ได้ เช่น "billing:*"
+type RoleDenyReq struct {
+	RoleName string `json:"role_name"`
+	PermName string `json:"perm_name"`
+}
+
+type UserDenyReq struct {
+	UserID   string `json:"user_id"`
+	PermName string `json:"perm_name"`
+}
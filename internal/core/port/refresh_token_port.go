@@ -0,0 +1,17 @@
+package port
+
+import (
+	"context"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *domain.RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error)
+	// Rotate ปิด Token เก่าและสร้าง Token ใหม่ใน Transaction เดียวกัน
+	// คืน domain.ErrRefreshTokenReused ถ้า Token เก่าถูกใช้ไปแล้ว (เช่นมี Request ซ้อนกัน)
+	Rotate(ctx context.Context, old *domain.RefreshToken, next *domain.RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID string) error
+}
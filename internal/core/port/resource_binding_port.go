@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+)
+
+type ResourceBindingRepository interface {
+	Create(ctx context.Context, binding *domain.ResourceRoleBinding) error
+	Delete(ctx context.Context, userID string, roleID string, resourceType string, resourceID string) error
+	// GetByUserUID คืน Binding ทั้งหมดของ User (Preload Role มาด้วย)
+	GetByUserUID(ctx context.Context, userID string) ([]domain.ResourceRoleBinding, error)
+}
@@ -0,0 +1,104 @@
+package port
+
+import "time"
+
+// --- API Response DTOs ---
+// Handler แปลง Domain Object เป็น DTO ก่อนส่งออกเสมอ
+// จะได้ไม่หลุด Field ภายใน (Password Hash, Seq) ไปกับ JSON
+
+// UserResponse ข้อมูล User ที่ส่งออกทาง API (ไม่มี Password Hash)
+type UserResponse struct {
+	ID                 string     `json:"id"`
+	Username           string     `json:"username"`
+	Email              string     `json:"email"`
+	Kind               string     `json:"kind"`
+	Description        string     `json:"description,omitempty"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at,omitempty"`
+	DisabledAt         *time.Time `json:"disabled_at,omitempty"`
+	MustChangePassword bool       `json:"must_change_password"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"`
+}
+
+type UserPage struct {
+	Users    []UserResponse `json:"users"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+	Total    int64          `json:"total"`
+}
+
+type PermissionResponse struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ExpandedPermissionResponse: Covers คือ Permission จริงที่ Wildcard นี้ครอบคลุม
+type ExpandedPermissionResponse struct {
+	PermissionResponse
+	Covers []string `json:"covers,omitempty"`
+}
+
+// RolePermissionResponse Permission ของ Role พร้อม Condition (ถ้ามี)
+type RolePermissionResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Condition string `json:"condition,omitempty"`
+}
+
+type RoleRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type RoleResponse struct {
+	ID          string                   `json:"id"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Permissions []RolePermissionResponse `json:"permissions"`
+	Parents     []RoleRef                `json:"parents,omitempty"`
+	Denies      []string                 `json:"denies,omitempty"`
+	CreatedAt   time.Time                `json:"created_at"`
+	UpdatedAt   time.Time                `json:"updated_at"`
+	DeletedAt   *time.Time               `json:"deleted_at,omitempty"`
+}
+
+// ResourceBindingResponse Role ที่ User ถือบน Resource หนึ่งตัว
+type ResourceBindingResponse struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"user_id"`
+	Role         RoleRef   `json:"role"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type TenantResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TenantRoleResponse Role ที่ User ถือใน Tenant หนึ่ง (TenantName ว่างถ้าไม่ได้ Preload Tenant มา)
+type TenantRoleResponse struct {
+	ID         string     `json:"id"`
+	TenantID   string     `json:"tenant_id"`
+	TenantName string     `json:"tenant_name,omitempty"`
+	UserID     string     `json:"user_id"`
+	Role       RoleRef    `json:"role"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type UserDenyResponse struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+}
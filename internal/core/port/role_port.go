@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
 )
@@ -9,10 +10,47 @@ import (
 type RoleRepository interface {
 	Create(ctx context.Context, role *domain.Role) error
 	GetAll(ctx context.Context) ([]domain.Role, error)
+	// GetRoleByUserUID คืนเฉพาะ Role ที่ Assignment ยังอยู่ในช่วงเวลาใช้งาน
 	GetRoleByUserUID(ctx context.Context, uid string) ([]domain.Role, error)
+	// GetNextRoleChange เวลาถัดไปที่ Assignment ของ User จะเริ่ม/หมดอายุ (nil = ไม่มี)
+	GetNextRoleChange(ctx context.Context, uid string, now time.Time) (*time.Time, error)
 	GetRoleByName(ctx context.Context, name string) (*domain.Role, error)
-	AddAccosiatePermission(ctx context.Context, roleID string, permID string) error
+	GetRoleByUID(ctx context.Context, uid string) (*domain.Role, error)
+	// Update บันทึก Name / Description
+	Update(ctx context.Context, role *domain.Role) error
+	// Delete เป็น Soft Delete: cascade = ลบความสัมพันธ์ทั้งหมดทิ้งถาวร (Restore แล้วจะได้ Role เปล่า)
+	// ไม่ cascade = เก็บความสัมพันธ์ไว้ (Policy ไม่เห็น Role ที่ถูกลบ) Restore แล้วกลับมาครบ
+	Delete(ctx context.Context, uid string, cascade bool) error
+	Restore(ctx context.Context, uid string) error
+	GetDeleted(ctx context.Context) ([]domain.Role, error)
+	// AddAccosiatePermission ผูก Permission ให้ Role (ถ้าผูกอยู่แล้วจะอัปเดต Condition)
+	AddAccosiatePermission(ctx context.Context, roleID string, permID string, condition string) error
 	RemoveAssociatePermission(ctx context.Context, roleID string, permID string) error
+	AddParent(ctx context.Context, roleID string, parentID string) error
+	RemoveParent(ctx context.Context, roleID string, parentID string) error
+	// GetRoleHolders คืน User ที่ถือ Role ในรายการอยู่ตอนนี้ จากทุกที่ (user_roles, tenant_user_roles, Binding)
+	GetRoleHolders(ctx context.Context, roleNames []string, filter RoleHolderFilter) ([]RoleHolderRow, error)
+}
+
+// RoleHolderFilter ว่าง = ไม่กรอง (Scope ใช้ค่า RoleOrigin*)
+type RoleHolderFilter struct {
+	Search   string // ค้นจาก username / email
+	Scope    string
+	TenantID string
+	// ActiveOnly ข้าม User ที่ถูก Disable (ถือ Role อยู่แต่ใช้สิทธิ์ไม่ได้) ส่วนการลบ Cache ต้องได้ทุกคน
+	ActiveOnly bool
+}
+
+// RoleHolderRow 1 แถวต่อ 1 Assignment (User หนึ่งคนมีได้หลายแถว)
+type RoleHolderRow struct {
+	UserUid      string
+	Username     string
+	Email        string
+	RoleName     string
+	Scope        string
+	TenantUid    *string
+	ResourceType string
+	ResourceID   string
 }
 
 type RoleService interface {
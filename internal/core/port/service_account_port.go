@@ -0,0 +1,72 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	GetByUID(ctx context.Context, uid string) (*domain.APIKey, error)
+	ListByUser(ctx context.Context, userID string) ([]domain.APIKey, error)
+	Revoke(ctx context.Context, uid string) error
+	RevokeAllForUser(ctx context.Context, userID string) error
+	SetExpiry(ctx context.Context, uid string, at time.Time) error
+	TouchLastUsed(ctx context.Context, uid string, at time.Time) error
+}
+
+// ServiceAccountService Service Account คือ User ชนิด domain.UserKindService
+// ถือ Role / Binding / Deny ได้เหมือน User ทุกอย่าง (ใช้ id เดียวกันกับ Endpoint ของ User)
+type ServiceAccountService interface {
+	CreateServiceAccount(ctx context.Context, req *CreateServiceAccountReq) (*domain.User, error)
+	ListServiceAccounts(ctx context.Context) ([]domain.User, error)
+	// DeleteServiceAccount ลบแบบ Soft Delete และเพิกถอน API Key ทั้งหมด
+	DeleteServiceAccount(ctx context.Context, accountID string) error
+
+	// CreateAPIKey คืน Key ตัวจริงครั้งเดียว (หลังจากนี้ดูได้แค่ Prefix)
+	CreateAPIKey(ctx context.Context, accountID string, req *CreateAPIKeyReq) (*domain.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, accountID string) ([]domain.APIKey, error)
+	// RotateAPIKey ออก Key ใหม่ชื่อเดิม Key เก่าใช้ได้ต่ออีก grace แต่ไม่เกินวันหมดอายุเดิม (0 = เพิกถอนทันที)
+	RotateAPIKey(ctx context.Context, accountID string, keyID string, grace time.Duration) (*domain.APIKey, string, error)
+	RevokeAPIKey(ctx context.Context, accountID string, keyID string) error
+
+	APIKeyAuthenticator
+}
+
+// APIKeyAuthenticator ใช้ใน Middleware (Header X-API-Key)
+type APIKeyAuthenticator interface {
+	// AuthenticateAPIKey คืน uid ของ Service Account เจ้าของ Key หรือ domain.ErrInvalidAPIKey
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (string, error)
+}
+
+// --- DTOs ---
+
+type CreateServiceAccountReq struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type CreateAPIKeyReq struct {
+	Name      string     `json:"name"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// APIKeyResponse ไม่มี Key ตัวจริง / Hash
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// CreatedAPIKeyResponse ตอนสร้าง / Rotate เท่านั้นที่เห็น Key ตัวจริง
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,42 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+)
+
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *domain.Tenant) error
+	GetAll(ctx context.Context) ([]domain.Tenant, error)
+	GetTenantByUID(ctx context.Context, uid string) (*domain.Tenant, error)
+
+	AddRoleAssignment(ctx context.Context, assignment *domain.TenantRoleAssignment) error
+	RemoveRoleAssignment(ctx context.Context, tenantID string, userID string, roleID string) error
+	// GetAssignmentsByUserUID คืน Assignment ทุก Tenant ของ User (Preload Tenant + Role)
+	GetAssignmentsByUserUID(ctx context.Context, userID string) ([]domain.TenantRoleAssignment, error)
+	// GetRolesByTenantAndUser / IsMember นับเฉพาะ Assignment ที่อยู่ในช่วงเวลาใช้งาน
+	GetRolesByTenantAndUser(ctx context.Context, tenantID string, userID string) ([]domain.Role, error)
+	GetNextRoleChange(ctx context.Context, tenantID string, userID string, now time.Time) (*time.Time, error)
+	IsMember(ctx context.Context, tenantID string, userID string) (bool, error)
+	DeleteExpiredAssignments(ctx context.Context, now time.Time) ([]domain.TenantRoleAssignment, error)
+}
+
+// --- Tenant ใน Context ---
+// Tenant ของ Request ถูกส่งผ่าน ctx ไปยัง CheckAccess / CheckAccessOn
+// ctx ที่ไม่มี Tenant = เช็คเฉพาะ Role ระดับ Platform
+
+type tenantCtxKey struct{}
+
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	if tenantID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, tenantCtxKey{}, tenantID)
+}
+
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantCtxKey{}).(string)
+	return tenantID
+}
@@ -0,0 +1,38 @@
+package port
+
+import (
+	"context"
+	"time"
+)
+
+// TokenVerifier ตรวจ Access Token แล้วคืน Claims ที่ผ่านการตรวจแล้ว
+// Error ที่คืนเป็น domain.ErrToken* เสมอ (ใช้ errors.Is แยกชนิดได้)
+type TokenVerifier interface {
+	Verify(tokenString string) (*AccessClaims, error)
+}
+
+type AccessClaims struct {
+	TokenID   string
+	UserID    string
+	Username  string
+	Email     string
+	TenantID  string // ว่างถ้า Token ไม่ได้ผูกกับ Tenant
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// TokenDenylist เก็บรายการ Access Token ที่ถูกเพิกถอนก่อนหมดอายุ
+type TokenDenylist interface {
+	// Deny เพิกถอน Token ตาม jti จนกว่าจะครบ ttl (ปกติคือเวลาที่เหลือก่อน exp)
+	Deny(ctx context.Context, jti string, ttl time.Duration) error
+	IsDenied(ctx context.Context, jti string) (bool, error)
+
+	// RevokeUser เพิกถอนทุก Token ของ User ที่ออกก่อนเวลา at
+	RevokeUser(ctx context.Context, userID string, at time.Time, ttl time.Duration) error
+	// RevokedBefore คืนเวลาที่สั่ง RevokeUser ล่าสุด (zero time ถ้าไม่เคย)
+	RevokedBefore(ctx context.Context, userID string) (time.Time, error)
+
+	// SetUserDisabled / IsUserDisabled ให้ Middleware ปฏิเสธ User ที่ถูกระงับได้โดยไม่ต้องยิง DB
+	SetUserDisabled(ctx context.Context, userID string, disabled bool) error
+	IsUserDisabled(ctx context.Context, userID string) (bool, error)
+}
@@ -0,0 +1,9 @@
+package port
+
+import "context"
+
+// Transactor รวมงานของหลาย Repository ไว้ใน Transaction เดียว
+// Repository ที่ได้ ctx ของ fn จะเข้าร่วม Transaction นั้นเอง (fn คืน Error = Rollback ทั้งหมด)
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
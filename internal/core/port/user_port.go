@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
 )
@@ -10,6 +11,61 @@ type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
 	GetUserByUID(ctx context.Context, uid string) (*domain.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
-	AddAccosiateRole(ctx context.Context, userID string, roleID string) error
+	// GetUserByEmail ไม่สนตัวพิมพ์เล็ก/ใหญ่
+	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	// AddAccosiateRole ถ้ามี Assignment อยู่แล้วจะอัปเดตช่วงเวลาใหม่ (validFrom / validUntil เป็น nil = ไม่จำกัด)
+	AddAccosiateRole(ctx context.Context, userID string, roleID string, validFrom *time.Time, validUntil *time.Time) error
 	RemoveAssociateRole(ctx context.Context, userID string, roleID string) error
+	// DeleteExpiredRoles ลบ Assignment ที่หมดอายุแล้ว คืนรายการที่ถูกลบ
+	DeleteExpiredRoles(ctx context.Context, now time.Time) ([]domain.UserRole, error)
+
+	// --- User Management ---
+	List(ctx context.Context, filter UserFilter) ([]domain.User, int64, error)
+	// Update บันทึก username, email, email_verified_at, password, disabled_at, must_change_password
+	Update(ctx context.Context, user *domain.User) error
+	Delete(ctx context.Context, uid string) error
+	Restore(ctx context.Context, uid string) error
+}
+
+// UserService งานจัดการ User ของ Admin
+type UserService interface {
+	ListUsers(ctx context.Context, query *UserListQuery) ([]domain.User, int64, error)
+	GetUser(ctx context.Context, userID string) (*domain.User, error)
+	UpdateUser(ctx context.Context, userID string, req *UpdateUserReq) (*domain.User, error)
+	// Disable / Delete เพิกถอนทุก Session ทันที (Service Account เพิกถอน API Key ทั้งหมดด้วย)
+	DisableUser(ctx context.Context, userID string) error
+	EnableUser(ctx context.Context, userID string) error
+	DeleteUser(ctx context.Context, userID string) error
+	RestoreUser(ctx context.Context, userID string) error
+	// ForcePasswordReset บังคับเปลี่ยนรหัสผ่าน (Login ไม่ได้จนกว่าจะเปลี่ยน) และเพิกถอนทุก Session
+	ForcePasswordReset(ctx context.Context, userID string) error
+}
+
+// สถานะของ User ที่ใช้กรองใน List (ว่าง = ทุกคนที่ยังไม่ถูกลบ)
+const (
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+	UserStatusDeleted  = "deleted"
+)
+
+type UserListQuery struct {
+	Search   string // username / email
+	Status   string
+	Kind     string // domain.UserKind* (ว่าง = ทุกชนิด)
+	Page     int
+	PageSize int
+}
+
+type UserFilter struct {
+	Search string
+	Status string
+	Kind   string
+	Limit  int // -1 = ทั้งหมด
+	Offset int
+}
+
+// UpdateUserReq: Field ที่เป็น nil = ไม่เปลี่ยน
+type UpdateUserReq struct {
+	Username *string `json:"username"`
+	Email    *string `json:"email"`
 }
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// notifyTimeout เวลาสูงสุดที่ให้ Notifier ส่งหนึ่งข้อความ (ส่งใน Background ไม่ให้ Response ช้าตาม SMTP)
+const notifyTimeout = 30 * time.Second
+
+type accountTokenService struct {
+	userRepo       port.UserRepository
+	tokenRepo      port.UserTokenRepository
+	authSvc        port.AuthService
+	attempts       port.LoginAttemptStore
+	passwordPolicy port.PasswordPolicy
+	notifier       port.Notifier
+	tx             port.Transactor
+	audit          port.AuditLogger
+	cfg            config.AccountTokensConfig
+}
+
+func NewAccountTokenService(userRepo port.UserRepository, tokenRepo port.UserTokenRepository, authSvc port.AuthService, attempts port.LoginAttemptStore, passwordPolicy port.PasswordPolicy, notifier port.Notifier, tx port.Transactor, audit port.AuditLogger, cfg config.AccountTokensConfig) port.AccountTokenService {
+	return &accountTokenService{
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		authSvc:        authSvc,
+		attempts:       attempts,
+		passwordPolicy: passwordPolicy,
+		notifier:       notifier,
+		tx:             tx,
+		audit:          audit,
+		cfg:            cfg,
+	}
+}
+
+// RequestPasswordReset ตอบเหมือนกันทุกกรณี (ไม่มีอีเมลนี้ / ถูกระงับ / ขอบ่อยเกิน) เพื่อไม่ให้ใช้เดาว่าอีเมลไหนมีบัญชี
+func (s *accountTokenService) RequestPasswordReset(ctx context.Context, req *port.PasswordResetRequestReq) error {
+	email := strings.TrimSpace(req.Email)
+	if msg := domain.ValidateEmail(email); msg != "" {
+		verr := &domain.ValidationError{}
+		verr.Add("email", msg)
+		return verr
+	}
+
+	if !s.allowRequest(ctx, "mail:reset:"+strings.ToLower(email)) {
+		return nil
+	}
+	if req.IP != "" && !s.allowRequest(ctx, "mail:ip:"+req.IP) {
+		return nil
+	}
+
+	// ค้นหา / ออก Token / บันทึก Audit / ส่งอีเมล ทำเบื้องหลังทั้งหมด
+	// เวลาตอบจึงเท่ากันไม่ว่าอีเมลนี้จะมีบัญชีหรือไม่ (เก็บ Request Meta ไว้ให้ Audit)
+	go s.sendPasswordReset(context.WithoutCancel(ctx), email)
+	return nil
+}
+
+// sendPasswordReset ทำงานใน Background (Error แค่ Log เพราะตอบ Client ไปแล้ว)
+func (s *accountTokenService) sendPasswordReset(ctx context.Context, email string) {
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if errors.Is(err, domain.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		log.Printf("⚠️ Failed to look up password reset email: %v", err)
+		return
+	}
+	if user.IsServiceAccount() || user.DisabledAt != nil {
+		return
+	}
+
+	raw, err := s.issue(ctx, user, domain.UserTokenPasswordReset, s.cfg.PasswordResetTTL)
+	if err != nil {
+		log.Printf("⚠️ Failed to issue password reset token for %s: %v", user.Uid, err)
+		return
+	}
+	s.audit.Record(ctx, port.AuditEntry{Action: domain.AuditPasswordResetRequest, TargetType: port.AuditTargetUser, TargetID: user.Uid.String()})
+	s.send(&port.Notification{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s and can be used once.\n\n%s\n\nIf you did not ask for this, you can ignore this email.",
+			user.Username, s.cfg.PasswordResetTTL, s.link("/reset-password", raw)),
+	})
+}
+
+// ResetPassword ตรวจรหัสผ่านใหม่ก่อนใช้ Token (รหัสไม่ผ่าน Policy ก็ยังใช้ลิงก์เดิมลองใหม่ได้)
+func (s *accountTokenService) ResetPassword(ctx context.Context, req *port.PasswordResetReq) error {
+	token, user, err := s.lookup(ctx, domain.UserTokenPasswordReset, req.Token)
+	if err != nil {
+		return err
+	}
+	if user.DisabledAt != nil {
+		return domain.ErrUserDisabled
+	}
+
+	verr := &domain.ValidationError{}
+	for _, msg := range s.passwordPolicy.Validate(req.NewPassword, user.Username) {
+		verr.Add("new_password", msg)
+	}
+	if err := verr.OrNil(); err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcryptCost)
+	if err != nil {
+		return err
+	}
+
+	user.Password = string(hashed)
+	user.MustChangePassword = false
+	// เปิดลิงก์จากอีเมลได้ = เป็นเจ้าของอีเมลนี้
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	userID := user.Uid.String()
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.tokenRepo.MarkUsed(ctx, token.Uid.String()); err != nil {
+			return port.AuditEntry{}, err
+		}
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditPasswordReset, TargetType: port.AuditTargetUser, TargetID: userID, ActorID: userID}, nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.authSvc.RevokeAllSessions(ctx, userID); err != nil {
+		return err
+	}
+	return s.authSvc.UnlockAccount(ctx, userID)
+}
+
+func (s *accountTokenService) RequestEmailVerification(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetUserByUID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.IsServiceAccount() {
+		return fmt.Errorf("%w: service accounts have no mailbox", domain.ErrValidation)
+	}
+	if user.EmailVerifiedAt != nil {
+		return domain.ErrEmailAlreadyVerified
+	}
+	if !s.allowRequest(ctx, "mail:verify:"+userID) {
+		return domain.ErrTooManyTokenRequests
+	}
+
+	raw, err := s.issue(ctx, user, domain.UserTokenEmailVerification, s.cfg.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	s.send(&port.Notification{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm that this address belongs to you by opening the link below. It expires in %s.\n\n%s",
+			user.Username, s.cfg.EmailVerificationTTL, s.link("/verify-email", raw)),
+	})
+	return nil
+}
+
+func (s *accountTokenService) VerifyEmail(ctx context.Context, req *port.VerifyEmailReq) error {
+	token, user, err := s.lookup(ctx, domain.UserTokenEmailVerification, req.Token)
+	if err != nil {
+		return err
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.tokenRepo.MarkUsed(ctx, token.Uid.String()); err != nil {
+			return err
+		}
+		if user.EmailVerifiedAt != nil {
+			return nil
+		}
+
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		userID := user.Uid.String()
+		return s.audit.Record(ctx, port.AuditEntry{Action: domain.AuditEmailVerify, TargetType: port.AuditTargetUser, TargetID: userID, ActorID: userID, After: map[string]any{"email": user.Email}})
+	})
+}
+
+// issue ยกเลิก Token เก่าที่ยังไม่ได้ใช้ แล้วออกใหม่ (คืน Token ตัวจริง เก็บแค่ Hash)
+func (s *accountTokenService) issue(ctx context.Context, user *domain.User, purpose string, ttl time.Duration) (string, error) {
+	if err := s.tokenRepo.InvalidateForUser(ctx, user.Uid.String(), purpose); err != nil {
+		return "", err
+	}
+
+	raw, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	token := &domain.UserToken{
+		UserUid:   user.Uid,
+		Purpose:   purpose,
+		TokenHash: hashToken(raw),
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// lookup คืน domain.ErrInvalidUserToken ทุกกรณีที่ใช้ไม่ได้ (ไม่บอกว่าเพราะอะไร)
+func (s *accountTokenService) lookup(ctx context.Context, purpose string, raw string) (*domain.UserToken, *domain.User, error) {
+	if raw == "" {
+		return nil, nil, domain.ErrInvalidUserToken
+	}
+
+	token, err := s.tokenRepo.GetByHash(ctx, purpose, hashToken(raw))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil, domain.ErrInvalidUserToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !token.Usable(time.Now()) {
+		return nil, nil, domain.ErrInvalidUserToken
+	}
+
+	user, err := s.userRepo.GetUserByUID(ctx, token.UserUid.String())
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil, domain.ErrInvalidUserToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	// เปลี่ยนอีเมลไปแล้ว: ลิงก์ที่ส่งไปอีเมลเดิมใช้ไม่ได้
+	if !strings.EqualFold(user.Email, token.Email) {
+		return nil, nil, domain.ErrInvalidUserToken
+	}
+	return token, user, nil
+}
+
+// allowRequest นับจำนวนครั้งที่ขอส่งอีเมลต่อ key (Redis ล่ม = ไม่จำกัด เหมือน Login Throttle)
+func (s *accountTokenService) allowRequest(ctx context.Context, key string) bool {
+	if s.cfg.MaxRequests <= 0 {
+		return true
+	}
+	count, err := s.attempts.RecordFailure(ctx, key, s.cfg.RequestWindow)
+	if err != nil {
+		log.Printf("⚠️ Failed to count email request for %s: %v", key, err)
+		return true
+	}
+	return count <= s.cfg.MaxRequests
+}
+
+func (s *accountTokenService) link(path string, token string) string {
+	return strings.TrimRight(s.cfg.LinkBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// send ส่งใน Background: Response ไม่ช้าตาม SMTP และเวลาตอบไม่บอกว่าอีเมลมีในระบบหรือไม่
+func (s *accountTokenService) send(msg *port.Notification) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := s.notifier.Send(ctx, msg); err != nil {
+			log.Printf("⚠️ Failed to send %q to %s: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
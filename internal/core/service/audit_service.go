@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+	auditVerifyBatchSize = 1000
+	// auditDeniedQueueSize จำนวนการปฏิเสธที่รอเขียนได้ เต็มแล้วทิ้ง (ไม่ให้ CheckAccess ต้องรอ DB)
+	auditDeniedQueueSize = 1024
+	// auditDropLogEvery Log จำนวนที่ทิ้งไปทุกๆ เท่านี้รายการ (ไม่ให้ Log ท่วมตอน DB ช้า)
+	auditDropLogEvery = 1000
+)
+
+type auditService struct {
+	repo port.AuditRepository
+	cfg  config.AuditConfig
+
+	// denied: Event ปฏิเสธสิทธิ์ที่สุ่มได้ รอ Worker เขียนทีละรายการ
+	denied        chan *domain.AuditEvent
+	deniedDropped atomic.Uint64
+}
+
+func NewAuditService(repo port.AuditRepository, cfg config.AuditConfig) port.AuditService {
+	s := &auditService{repo: repo, cfg: cfg}
+	if cfg.DeniedSampleRate > 0 {
+		s.denied = make(chan *domain.AuditEvent, auditDeniedQueueSize)
+		go s.writeDenied()
+	}
+	return s
+}
+
+// Record บันทึกแบบ Synchronous (ต่อท้าย Chain ทันที)
+func (s *auditService) Record(ctx context.Context, entry port.AuditEntry) error {
+	event := s.newEvent(ctx, entry)
+
+	// Client ตัดการเชื่อมต่อไปแล้ว (ctx ถูกยกเลิก) ก็ยังต้องบันทึก
+	if err := s.repo.Append(context.WithoutCancel(ctx), event); err != nil {
+		log.Printf("🚨 Failed to write audit event %s (%s %s): %v", event.Action, event.TargetType, event.TargetID, err)
+		return fmt.Errorf("%w: %s", domain.ErrAuditWriteFailed, event.Action)
+	}
+	return nil
+}
+
+// auditedTx ทำ mutate แล้วเขียน Audit Event ใน Transaction เดียวกัน: สำเร็จทั้งคู่หรือไม่มีอะไรเปลี่ยนเลย
+// งานนอก DB (Redis / Reload Policy) ต้องทำหลังจากนี้ เมื่อ Commit แล้วเท่านั้น
+func auditedTx(ctx context.Context, tx port.Transactor, audit port.AuditLogger, mutate func(ctx context.Context) (port.AuditEntry, error)) error {
+	return tx.WithinTx(ctx, func(ctx context.Context) error {
+		entry, err := mutate(ctx)
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, entry)
+	})
+}
+
+// newEvent อ่าน Actor / Request Meta จาก ctx ตอนนี้เลย (Meta เป็น Pointer ที่ Request ยังแก้ต่อได้)
+func (s *auditService) newEvent(ctx context.Context, entry port.AuditEntry) *domain.AuditEvent {
+	event := &domain.AuditEvent{
+		// ตัดให้เหลือ Microsecond เท่าที่ Postgres เก็บ ไม่งั้น Hash ที่คำนวณตอนตรวจจะไม่ตรง
+		OccurredAt: time.Now().UTC().Truncate(time.Microsecond),
+		ActorType:  domain.AuditActorSystem,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Outcome:    entry.Outcome,
+		Before:     auditJSON(entry.Before),
+		After:      auditJSON(entry.After),
+		TenantID:   port.TenantFromContext(ctx),
+	}
+	if event.Outcome == "" {
+		event.Outcome = domain.AuditSuccess
+	}
+	if meta := port.RequestMetaFromContext(ctx); meta != nil {
+		event.RequestID = meta.RequestID
+		event.IP = meta.IP
+		event.ActorID = meta.ActorID
+		event.ActorType = meta.ActorType
+		if event.ActorType == "" {
+			event.ActorType = domain.AuditActorAnonymous
+		}
+	}
+	if entry.ActorID != "" {
+		event.ActorID = entry.ActorID
+		if event.ActorType == domain.AuditActorAnonymous {
+			event.ActorType = domain.AuditActorUser
+		}
+	}
+	return event
+}
+
+// RecordDenied ไม่รอ DB: ส่งเข้าคิวให้ Worker เขียน ถ้าคิวเต็มก็ทิ้งและนับไว้
+func (s *auditService) RecordDenied(ctx context.Context, userID string, perm string, resource *port.Resource, reason string) {
+	if s.denied == nil || rand.Float64() >= s.cfg.DeniedSampleRate {
+		return
+	}
+	event := s.newEvent(ctx, port.AuditEntry{
+		Action:     domain.AuditAccessDenied,
+		TargetType: port.AuditTargetPermission,
+		TargetID:   perm,
+		Outcome:    domain.AuditDenied,
+		After:      map[string]any{"user_id": userID, "resource": resource, "reason": reason},
+		ActorID:    userID,
+	})
+
+	select {
+	case s.denied <- event:
+	default:
+		if n := s.deniedDropped.Add(1); n%auditDropLogEvery == 1 {
+			log.Printf("⚠️ Audit denied queue full, dropped %d sampled denials so far", n)
+		}
+	}
+}
+
+func (s *auditService) writeDenied() {
+	for event := range s.denied {
+		if err := s.repo.Append(context.Background(), event); err != nil {
+			log.Printf("🚨 Failed to write audit event %s (%s %s): %v", event.Action, event.TargetType, event.TargetID, err)
+		}
+	}
+}
+
+func (s *auditService) Query(ctx context.Context, query *port.AuditQuery) ([]domain.AuditEvent, int64, error) {
+	switch query.Outcome {
+	case "", domain.AuditSuccess, domain.AuditFailure, domain.AuditDenied:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown outcome %q", domain.ErrInvalidQuery, query.Outcome)
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return nil, 0, fmt.Errorf("%w: from must be before to", domain.ErrInvalidQuery)
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 {
+		query.PageSize = defaultAuditPageSize
+	}
+	query.PageSize = min(query.PageSize, maxAuditPageSize)
+
+	filter := port.AuditFilter{
+		ActorID:    query.ActorID,
+		TargetType: query.TargetType,
+		TargetID:   query.TargetID,
+		Outcome:    query.Outcome,
+		RequestID:  query.RequestID,
+		From:       query.From,
+		To:         query.To,
+		Limit:      query.PageSize,
+		Offset:     (query.Page - 1) * query.PageSize,
+	}
+	if prefix, ok := strings.CutSuffix(query.Action, "*"); ok {
+		filter.ActionPrefix = prefix
+	} else {
+		filter.Action = query.Action
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Verify ไล่จากรายการแรก: Seq ต้องต่อเนื่อง, PrevHash ต้องตรงกับรายการก่อนหน้า, Hash ต้องคำนวณได้ตรง
+func (s *auditService) Verify(ctx context.Context) (*port.AuditVerification, error) {
+	result := &port.AuditVerification{Valid: true}
+	for {
+		events, err := s.repo.ListAfter(ctx, result.HeadSeq, auditVerifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range events {
+			e := &events[i]
+			switch {
+			case e.Seq != result.HeadSeq+1:
+				return broken(result, result.HeadSeq+1, "sequence gap (event missing)"), nil
+			case e.PrevHash != result.HeadHash:
+				return broken(result, e.Seq, "prev_hash does not match the previous event"), nil
+			case e.ComputeHash() != e.Hash:
+				return broken(result, e.Seq, "hash mismatch (event modified)"), nil
+			}
+			result.Checked++
+			result.HeadSeq = e.Seq
+			result.HeadHash = e.Hash
+		}
+
+		if len(events) < auditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
+
+func broken(result *port.AuditVerification, seq int64, reason string) *port.AuditVerification {
+	result.Valid = false
+	result.BrokenAtSeq = seq
+	result.Reason = reason
+	return result
+}
+
+// auditJSON nil = ไม่มีข้อมูล (String ว่าง)
+func auditJSON(v any) string {
+	if v == nil {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%q", fmt.Sprint(v))
+	}
+	return string(b)
+}
+
+// --- Snapshot สำหรับ Before / After (เลือกเฉพาะ Field ที่มีความหมาย ไม่เอา Relation / Hash) ---
+
+func roleAuditView(r *domain.Role) map[string]any {
+	return map[string]any{"name": r.Name, "description": r.Description}
+}
+
+func permissionAuditView(p *domain.Permission) map[string]any {
+	return map[string]any{"name": p.Name, "description": p.Description}
+}
+
+func userAuditView(u *domain.User) map[string]any {
+	return map[string]any{
+		"username":             u.Username,
+		"email":                u.Email,
+		"kind":                 u.Kind,
+		"email_verified_at":    u.EmailVerifiedAt,
+		"disabled_at":          u.DisabledAt,
+		"must_change_password": u.MustChangePassword,
+		"deleted":              u.DeletedAt.Valid,
+	}
+}
+
+func apiKeyAuditView(k *domain.APIKey) map[string]any {
+	return map[string]any{"user_uid": k.UserUid, "name": k.Name, "prefix": k.Prefix, "expires_at": k.ExpiresAt}
+}
@@ -0,0 +1,484 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// --- MFA (TOTP) ---
+// Login ขั้นที่ 1 (รหัสผ่าน) ได้ Challenge Token อายุสั้น ขั้นที่ 2 เอา Challenge + Code มาแลก Token จริง
+// Challenge เป็น JWT ที่เซ็นด้วยกุญแจเดียวกับ Access Token แต่ aud ต่างกัน จึงใช้แทน Access Token ไม่ได้
+
+// mfaAudienceSuffix ต่อท้าย aud ของ Challenge Token (Token Verifier ของ Access Token จะปฏิเสธ)
+const mfaAudienceSuffix = ":mfa"
+
+type mfaChallengeClaims struct {
+	UserID   string `json:"user_id"`
+	TenantID string `json:"tid,omitempty"`
+	// Enroll = ยังไม่มี MFA แต่ถูกบังคับ ใช้ได้แค่กับ Endpoint ลงทะเบียน
+	Enroll bool `json:"enroll,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func (s *authService) issueChallenge(user *domain.User, tenantID *uuid.UUID, enroll bool) (*port.AuthResponse, error) {
+	now := time.Now()
+	claims := mfaChallengeClaims{
+		UserID: user.Uid.String(),
+		Enroll: enroll,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    s.issuer,
+			Subject:   user.Uid.String(),
+			Audience:  jwt.ClaimStrings{s.audience + mfaAudienceSuffix},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.mfa.ChallengeTTL)),
+		},
+	}
+	if tenantID != nil {
+		claims.TenantID = tenantID.String()
+	}
+
+	token, err := s.signToken(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &port.AuthResponse{
+		MFARequired:           true,
+		MFAEnrollmentRequired: enroll,
+		ChallengeToken:        token,
+	}, nil
+}
+
+// parseChallenge ตรวจ Signature / aud / exp และต้องยังไม่ถูกใช้ไปแล้ว
+func (s *authService) parseChallenge(ctx context.Context, token string) (*mfaChallengeClaims, error) {
+	var claims mfaChallengeClaims
+	_, err := s.challengeParser.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		alg, key, ok := s.keySet.VerificationKey(kid)
+		if !ok || alg != t.Method.Alg() {
+			return nil, domain.ErrInvalidMFAChallenge
+		}
+		return key, nil
+	})
+	if err != nil || claims.ID == "" {
+		return nil, domain.ErrInvalidMFAChallenge
+	}
+
+	denied, err := s.denylist.IsDenied(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if denied {
+		return nil, domain.ErrInvalidMFAChallenge
+	}
+	return &claims, nil
+}
+
+// consumeChallenge ใช้ Challenge ได้ครั้งเดียว
+func (s *authService) consumeChallenge(ctx context.Context, claims *mfaChallengeClaims) error {
+	return s.denylist.Deny(ctx, claims.ID, time.Until(claims.ExpiresAt.Time))
+}
+
+// recordChallengeFailure นับ Code ที่ผิดต่อ Challenge ครบ challenge_max_attempts = เผา Challenge ทิ้ง
+func (s *authService) recordChallengeFailure(ctx context.Context, claims *mfaChallengeClaims) {
+	if s.mfa.ChallengeMaxAttempts <= 0 {
+		return
+	}
+	count, err := s.throttle.store.RecordFailure(ctx, mfaChallengeAttemptKey(claims.ID), time.Until(claims.ExpiresAt.Time))
+	if err != nil {
+		log.Printf("⚠️ Failed to count MFA challenge failure: %v", err)
+		return
+	}
+	if count >= s.mfa.ChallengeMaxAttempts {
+		if err := s.consumeChallenge(ctx, claims); err != nil {
+			log.Printf("⚠️ Failed to revoke MFA challenge: %v", err)
+		}
+	}
+}
+
+// challengeUser ดึง User ล่าสุดของ Challenge (ระหว่างนั้นอาจถูกระงับไปแล้ว)
+func (s *authService) challengeUser(ctx context.Context, claims *mfaChallengeClaims) (*domain.User, *uuid.UUID, error) {
+	user, err := s.userRepo.GetUserByUID(ctx, claims.UserID)
+	if err != nil {
+		return nil, nil, domain.ErrInvalidMFAChallenge
+	}
+	if user.DisabledAt != nil {
+		return nil, nil, domain.ErrUserDisabled
+	}
+
+	var tenantID *uuid.UUID
+	if claims.TenantID != "" {
+		tid, err := uuid.Parse(claims.TenantID)
+		if err != nil {
+			return nil, nil, domain.ErrInvalidMFAChallenge
+		}
+		tenantID = &tid
+	}
+	return user, tenantID, nil
+}
+
+func (s *authService) VerifyMFA(ctx context.Context, req *port.MFAVerifyReq) (res *port.AuthResponse, err error) {
+	var user *domain.User
+	defer func() { s.recordLogin(ctx, domain.AuditLoginMFA, "", user, res, err) }()
+
+	claims, err := s.parseChallenge(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Enroll {
+		return nil, domain.ErrInvalidMFAChallenge // ยังไม่มี MFA ต้องไปลงทะเบียนก่อน
+	}
+	user, tenantID, err := s.challengeUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkSecondFactor(ctx, user, req.Code, req.RecoveryCode, req.IP); err != nil {
+		if errors.Is(err, domain.ErrInvalidMFACode) {
+			s.recordChallengeFailure(ctx, claims)
+		}
+		return nil, err
+	}
+	if err := s.consumeChallenge(ctx, claims); err != nil {
+		return nil, err
+	}
+	s.throttle.reset(ctx, user.Username, req.IP)
+	return s.issueTokens(ctx, user, tenantID, uuid.New(), nil)
+}
+
+func (s *authService) BeginChallengeEnrollment(ctx context.Context, challengeToken string) (*port.MFAEnrollment, error) {
+	claims, err := s.parseChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if !claims.Enroll {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	user, _, err := s.challengeUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	return s.beginEnrollment(ctx, user)
+}
+
+// ConfirmChallengeEnrollment ยืนยัน Code แรกแล้วออก Token ให้เลย (ไม่ต้อง Login ใหม่)
+func (s *authService) ConfirmChallengeEnrollment(ctx context.Context, req *port.MFAVerifyReq) (*port.MFAEnrollResult, error) {
+	claims, err := s.parseChallenge(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if !claims.Enroll {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	user, tenantID, err := s.challengeUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.confirmEnrollment(ctx, user, req.Code, req.IP)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidMFACode) {
+			s.recordChallengeFailure(ctx, claims)
+		}
+		return nil, err
+	}
+	s.recordLogin(ctx, domain.AuditMFAEnable, "", user, nil, nil)
+	if err := s.consumeChallenge(ctx, claims); err != nil {
+		return nil, err
+	}
+	s.throttle.reset(ctx, user.Username, req.IP)
+	tokens, err := s.issueTokens(ctx, user, tenantID, uuid.New(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &port.MFAEnrollResult{RecoveryCodes: codes, AuthResponse: tokens}, nil
+}
+
+func (s *authService) BeginMFAEnrollment(ctx context.Context, userID string) (*port.MFAEnrollment, error) {
+	user, err := s.userRepo.GetUserByUID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.beginEnrollment(ctx, user)
+}
+
+// ConfirmMFAEnrollment เปิด MFA แล้วเพิกถอนทุก Session (Session เดิมไม่ได้ผ่าน MFA)
+func (s *authService) ConfirmMFAEnrollment(ctx context.Context, userID string, code string) (*port.MFARecoveryCodes, error) {
+	user, err := s.userRepo.GetUserByUID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := s.confirmEnrollment(ctx, user, code, "")
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, port.AuditEntry{Action: domain.AuditMFAEnable, TargetType: port.AuditTargetUser, TargetID: userID})
+	if err := s.RevokeAllSessions(ctx, userID); err != nil {
+		return nil, err
+	}
+	return &port.MFARecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+func (s *authService) DisableMFA(ctx context.Context, userID string, code string) error {
+	user, err := s.userRepo.GetUserByUID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	enrolled, err := s.mfaEnrolled(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !enrolled {
+		return domain.ErrMFANotEnrolled
+	}
+	// ปิด MFA มีผลทุก Tenant: ถูกบังคับที่ Tenant ไหนก็ปิดไม่ได้
+	required, err := s.mfaRequiredInAnyTenant(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return domain.ErrMFARequired
+	}
+	if err := s.checkSecondFactor(ctx, user, code, "", ""); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.DeleteCredential(ctx, userID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, port.AuditEntry{Action: domain.AuditMFADisable, TargetType: port.AuditTargetUser, TargetID: userID})
+	return nil
+}
+
+func (s *authService) RegenerateRecoveryCodes(ctx context.Context, userID string, code string) (*port.MFARecoveryCodes, error) {
+	user, err := s.userRepo.GetUserByUID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSecondFactor(ctx, user, code, "", ""); err != nil {
+		return nil, err
+	}
+	codes, err := s.newRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, port.AuditEntry{Action: domain.AuditMFARecoveryCodes, TargetType: port.AuditTargetUser, TargetID: userID})
+	return &port.MFARecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// --- Helpers ---
+
+// beginEnrollment สร้าง Secret ใหม่ (ทับอันเดิมที่ยังไม่ยืนยัน) แต่ห้ามทับ MFA ที่เปิดใช้อยู่
+func (s *authService) beginEnrollment(ctx context.Context, user *domain.User) (*port.MFAEnrollment, error) {
+	cred, err := s.mfaRepo.GetCredential(ctx, user.Uid.String())
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if cred != nil && cred.ConfirmedAt != nil {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.SaveCredential(ctx, &domain.MFACredential{UserUid: user.Uid, Secret: secret}); err != nil {
+		return nil, err
+	}
+	return &port.MFAEnrollment{Secret: secret, URI: totpURI(s.mfa.Issuer, user.Username, secret)}, nil
+}
+
+func (s *authService) confirmEnrollment(ctx context.Context, user *domain.User, code string, ip string) ([]string, error) {
+	userID := user.Uid.String()
+	cred, err := s.mfaRepo.GetCredential(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cred.ConfirmedAt != nil {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	if err := s.throttle.checkMFA(ctx, userID, ip); err != nil {
+		return nil, err
+	}
+	ok, err := s.verifyTOTP(ctx, cred, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.throttle.recordMFAFailure(ctx, userID, ip)
+		return nil, domain.ErrInvalidMFACode
+	}
+	s.throttle.resetMFA(ctx, userID)
+
+	if err := s.mfaRepo.ConfirmCredential(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(ctx, userID)
+}
+
+// checkSecondFactor ตรวจ TOTP หรือ Recovery Code ผ่าน Throttle (กันเดา Code 6 หลัก)
+// Counter แยกจากรหัสผ่าน นับตาม uid และล้างเฉพาะตอนผ่าน (Login รหัสถูกซ้ำไม่ช่วยให้เดาต่อได้)
+func (s *authService) checkSecondFactor(ctx context.Context, user *domain.User, code string, recoveryCode string, ip string) error {
+	userID := user.Uid.String()
+	if err := s.throttle.checkMFA(ctx, userID, ip); err != nil {
+		return err
+	}
+
+	ok := false
+	if recoveryCode != "" {
+		used, err := s.mfaRepo.UseRecoveryCode(ctx, userID, hashToken(normalizeRecoveryCode(recoveryCode)))
+		if err != nil {
+			return err
+		}
+		ok = used
+	} else {
+		cred, err := s.mfaRepo.GetCredential(ctx, userID)
+		if errors.Is(err, domain.ErrNotFound) || (err == nil && cred.ConfirmedAt == nil) {
+			return domain.ErrMFANotEnrolled
+		}
+		if err != nil {
+			return err
+		}
+		if ok, err = s.verifyTOTP(ctx, cred, code); err != nil {
+			return err
+		}
+	}
+
+	if !ok {
+		s.throttle.recordMFAFailure(ctx, userID, ip)
+		return domain.ErrInvalidMFACode
+	}
+	s.throttle.resetMFA(ctx, userID)
+	return nil
+}
+
+// verifyTOTP Code ต้องตรง และ Time Step ต้องใหม่กว่าครั้งล่าสุดที่ใช้ (Code เดิมใช้ซ้ำไม่ได้)
+func (s *authService) verifyTOTP(ctx context.Context, cred *domain.MFACredential, code string) (bool, error) {
+	step, ok := matchTOTP(cred.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return s.mfaRepo.MarkStepUsed(ctx, cred.UserUid.String(), step)
+}
+
+// newRecoveryCodes สร้างชุดใหม่แทนชุดเดิม คืนตัวจริงให้ User ครั้งเดียว (เก็บแค่ Hash)
+func (s *authService) newRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	codes := make([]string, 0, s.mfa.RecoveryCodes)
+	hashes := make([]string, 0, s.mfa.RecoveryCodes)
+	for i := 0; i < s.mfa.RecoveryCodes; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// mfaStatus enrolled = เปิด MFA แล้ว, required = ถือ Role / Permission ที่บังคับ MFA (ใน Tenant ที่เลือกด้วย)
+func (s *authService) mfaStatus(ctx context.Context, userID string, tenantID *uuid.UUID) (bool, bool, error) {
+	enrolled, err := s.mfaEnrolled(ctx, userID)
+	if err != nil {
+		return false, false, err
+	}
+	if tenantID != nil {
+		ctx = port.WithTenant(ctx, tenantID.String())
+	}
+	required, err := s.holdsMFARequirement(ctx, userID)
+	if err != nil {
+		return false, false, err
+	}
+	return enrolled, required, nil
+}
+
+func (s *authService) mfaEnrolled(ctx context.Context, userID string) (bool, error) {
+	cred, err := s.mfaRepo.GetCredential(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return false, err
+	}
+	return cred != nil && cred.ConfirmedAt != nil, nil
+}
+
+// mfaRequiredInAnyTenant ดูระดับ Platform ก่อน แล้วไล่ทุก Tenant ที่ User มี Assignment
+func (s *authService) mfaRequiredInAnyTenant(ctx context.Context, userID string) (bool, error) {
+	if len(s.mfa.RequiredPermissions) == 0 && len(s.mfa.RequiredRoles) == 0 {
+		return false, nil
+	}
+	required, err := s.holdsMFARequirement(ctx, userID)
+	if err != nil || required {
+		return required, err
+	}
+
+	assignments, err := s.tenantRepo.GetAssignmentsByUserUID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	checked := make(map[uuid.UUID]bool, len(assignments))
+	for _, a := range assignments {
+		if checked[a.TenantUid] {
+			continue
+		}
+		checked[a.TenantUid] = true
+		required, err := s.holdsMFARequirement(port.WithTenant(ctx, a.TenantUid.String()), userID)
+		if err != nil || required {
+			return required, err
+		}
+	}
+	return false, nil
+}
+
+// holdsMFARequirement (Tenant ตาม ctx) Role นับจากชื่อ Role ที่ถือรวม Parent (ถึง Role นั้นไม่มี Permission เลยก็นับ)
+// ส่วน Permission นับ Wildcard ด้วย ("*" ครอบคลุม "system:admin") แต่ไม่นับที่โดน Deny
+func (s *authService) holdsMFARequirement(ctx context.Context, userID string) (bool, error) {
+	if len(s.mfa.RequiredRoles) > 0 {
+		roleNames, err := s.rbacSvc.GetUserRoleNames(ctx, userID)
+		if err != nil {
+			return false, err
+		}
+		for _, name := range roleNames {
+			if slices.Contains(s.mfa.RequiredRoles, name) {
+				return true, nil
+			}
+		}
+	}
+	if len(s.mfa.RequiredPermissions) == 0 {
+		return false, nil
+	}
+
+	effective, err := s.rbacSvc.GetEffectivePermissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, ep := range effective.Permissions {
+		for _, required := range s.mfa.RequiredPermissions {
+			if domain.PermissionMatches(ep.Permission, required) && !matchesAny(ep.Except, required) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func matchesAny(patterns []string, perm string) bool {
+	for _, p := range patterns {
+		if domain.PermissionMatches(p, perm) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/google/uuid"
+)
+
+// fakeMFARBAC Role (รวม Parent แล้ว) แยกตาม Tenant ("" = Platform) และไม่มี Permission เลย
+type fakeMFARBAC struct {
+	port.RBACService
+	roles map[string][]string
+}
+
+func (f fakeMFARBAC) GetUserRoleNames(ctx context.Context, _ string) ([]string, error) {
+	return f.roles[port.TenantFromContext(ctx)], nil
+}
+
+func (f fakeMFARBAC) GetEffectivePermissions(ctx context.Context, userID string) (*port.EffectivePermissions, error) {
+	return &port.EffectivePermissions{UserID: userID, TenantID: port.TenantFromContext(ctx)}, nil
+}
+
+type fakeMFATenantRepo struct {
+	port.TenantRepository
+	assignments []domain.TenantRoleAssignment
+}
+
+func (f fakeMFATenantRepo) GetAssignmentsByUserUID(context.Context, string) ([]domain.TenantRoleAssignment, error) {
+	return f.assignments, nil
+}
+
+func TestHoldsMFARequirementCountsRolesWithoutPermissions(t *testing.T) {
+	s := &authService{
+		rbacSvc: fakeMFARBAC{roles: map[string][]string{"": {"auditor", "security-admin"}}},
+		mfa:     config.MFAConfig{RequiredRoles: []string{"security-admin"}},
+	}
+	required, err := s.holdsMFARequirement(context.Background(), uuid.NewString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !required {
+		t.Error("required = false, want true for an inherited role that grants no permissions")
+	}
+}
+
+func TestMFARequiredInAnyTenant(t *testing.T) {
+	tenantA, tenantB := uuid.New(), uuid.New()
+	assignments := []domain.TenantRoleAssignment{{TenantUid: tenantA}, {TenantUid: tenantB}}
+
+	tests := []struct {
+		name  string
+		roles map[string][]string
+		want  bool
+	}{
+		{"platform role", map[string][]string{"": {"security-admin"}}, true},
+		{"second tenant only", map[string][]string{tenantA.String(): {"viewer"}, tenantB.String(): {"security-admin"}}, true},
+		{"no required role anywhere", map[string][]string{"": {"viewer"}, tenantA.String(): {"viewer"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &authService{
+				rbacSvc:    fakeMFARBAC{roles: tt.roles},
+				tenantRepo: fakeMFATenantRepo{assignments: assignments},
+				mfa:        config.MFAConfig{RequiredRoles: []string{"security-admin"}},
+			}
+			got, err := s.mfaRequiredInAnyTenant(context.Background(), uuid.NewString())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("mfaRequiredInAnyTenant() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,66 +2,460 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type authService struct {
-	userRepo  port.UserRepository
-	jwtSecret string
+	userRepo         port.UserRepository
+	refreshTokenRepo port.RefreshTokenRepository
+	tenantRepo       port.TenantRepository
+	mfaRepo          port.MFARepository
+	rbacSvc          port.RBACService
+	denylist         port.TokenDenylist
+	audit            port.AuditLogger
+	throttle         *loginThrottle
+	passwordPolicy   port.PasswordPolicy
+	keySet           port.KeySet
+	accessTokenTTL   time.Duration
+	refreshTokenTTL  time.Duration
+	issuer           string
+	audience         string
+	mfa              config.MFAConfig
+	challengeParser  *jwt.Parser
 }
 
-func NewAuthService(repo port.UserRepository, secret string) port.AuthService {
-	return &authService{userRepo: repo, jwtSecret: secret}
+// bcryptCost ใช้ทั้งตอน Hash รหัสผ่านจริงและ Dummy Hash (เวลาตรวจต้องเท่ากัน)
+const bcryptCost = 10
+
+func NewAuthService(repo port.UserRepository, refreshTokenRepo port.RefreshTokenRepository, tenantRepo port.TenantRepository, mfaRepo port.MFARepository, rbacSvc port.RBACService, denylist port.TokenDenylist, attempts port.LoginAttemptStore, passwordPolicy port.PasswordPolicy, keySet port.KeySet, audit port.AuditLogger, cfg config.AuthConfig) port.AuthService {
+	return &authService{
+		userRepo:         repo,
+		refreshTokenRepo: refreshTokenRepo,
+		tenantRepo:       tenantRepo,
+		mfaRepo:          mfaRepo,
+		rbacSvc:          rbacSvc,
+		denylist:         denylist,
+		audit:            audit,
+		throttle:         newLoginThrottle(attempts, cfg.Lockout),
+		passwordPolicy:   passwordPolicy,
+		keySet:           keySet,
+		accessTokenTTL:   cfg.AccessTokenTTL,
+		refreshTokenTTL:  cfg.RefreshTokenTTL,
+		issuer:           cfg.Issuer,
+		audience:         cfg.Audience,
+		mfa:              cfg.MFA,
+		challengeParser: jwt.NewParser(
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audience+mfaAudienceSuffix),
+			jwt.WithExpirationRequired(),
+		),
+	}
 }
 
 func (s *authService) Register(ctx context.Context, req *port.RegisterReq) error {
-	// 1. Hash Password
-	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), 10)
+	// 1. Validate (รวม Error ทุก Field แล้วตอบทีเดียว)
+	username := strings.TrimSpace(req.Username)
+	email := strings.TrimSpace(req.Email)
+
+	verr := &domain.ValidationError{}
+	if msg := domain.ValidateUsername(username); msg != "" {
+		verr.Add("username", msg)
+	}
+	if msg := domain.ValidateEmail(email); msg != "" {
+		verr.Add("email", msg)
+	}
+	for _, msg := range s.passwordPolicy.Validate(req.Password, username) {
+		verr.Add("password", msg)
+	}
+	if err := verr.OrNil(); err != nil {
+		return err
+	}
+
+	// 2. Hash Password
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcryptCost)
 	if err != nil {
 		return err
 	}
 
-	// 2. Prepare User
+	// 3. Prepare User
 	user := &domain.User{
-		Username: req.Username,
-		Email:    req.Email,
+		Username: username,
+		Email:    email,
 		Password: string(hashed),
 	}
 
-	// 3. Save to Repo
+	// 4. Save to Repo (Username / Email ซ้ำ = domain.ErrAlreadyExists)
 	return s.userRepo.Create(ctx, user)
 }
 
-func (s *authService) Login(ctx context.Context, req *port.LoginReq) (*port.AuthResponse, error) {
-	// 1. Find User
-	user, err := s.userRepo.GetUserByUsername(ctx, req.Username)
+func (s *authService) Login(ctx context.Context, req *port.LoginReq) (res *port.AuthResponse, err error) {
+	var user *domain.User
+	defer func() { s.recordLogin(ctx, domain.AuditLogin, req.Username, user, res, err) }()
+
+	// 1-2. Find User + Check Password (ผ่าน Throttle)
+	user, err = s.verifyCredentials(ctx, req.Username, req.Password, req.IP)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. บัญชีถูกระงับ / ถูกบังคับเปลี่ยนรหัสผ่าน (เช็คหลังรหัสผ่านถูก จะได้ไม่บอกสถานะบัญชีกับคนที่ไม่รู้รหัส)
+	if user.DisabledAt != nil {
+		return nil, domain.ErrUserDisabled
+	}
+	if user.MustChangePassword {
+		return nil, domain.ErrPasswordChangeRequired
+	}
+
+	// 4. ถ้าขอ Token สำหรับ Tenant ต้องเป็นสมาชิกของ Tenant นั้น
+	var tenantID *uuid.UUID
+	if req.TenantID != "" {
+		tid, err := uuid.Parse(req.TenantID)
+		if err != nil {
+			return nil, domain.ErrNotTenantMember
+		}
+		member, err := s.tenantRepo.IsMember(ctx, tid.String(), user.Uid.String())
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			return nil, domain.ErrNotTenantMember
+		}
+		tenantID = &tid
+	}
+
+	// 5. เปิด MFA ไว้ หรือถือ Role / Permission ที่บังคับ MFA -> ยังไม่ออก Token ให้ Challenge แทน
+	enrolled, required, err := s.mfaStatus(ctx, user.Uid.String(), tenantID)
+	if err != nil {
+		return nil, err
+	}
+	// Counter ของรหัสผ่านยังไม่ล้างจนกว่าจะผ่านขั้นที่ 2
+	if enrolled || required {
+		return s.issueChallenge(user, tenantID, !enrolled)
+	}
+
+	// 6. Generate Token คู่ใหม่ (เริ่ม Family ใหม่ทุกครั้งที่ Login)
+	s.throttle.reset(ctx, user.Username, req.IP)
+	return s.issueTokens(ctx, user, tenantID, uuid.New(), nil)
+}
+
+// Refresh แลก Refresh Token เป็น Token คู่ใหม่ และ Rotate Refresh Token ทุกครั้ง
+func (s *authService) Refresh(ctx context.Context, req *port.RefreshReq) (*port.AuthResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	// 1. หา Token จาก Hash
+	current, err := s.refreshTokenRepo.GetByHash(ctx, hashToken(req.RefreshToken))
+	if err != nil {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	// 2. Token ที่เคยถูก Rotate ไปแล้วถูกส่งกลับมาอีก = Reuse -> Revoke ทั้ง Family
+	if current.RevokedAt != nil {
+		if current.ReplacedBy != nil {
+			s.revokeFamily(ctx, current)
+			s.audit.Record(ctx, port.AuditEntry{
+				Action:     domain.AuditRefreshTokenReuse,
+				TargetType: port.AuditTargetUser,
+				TargetID:   current.UserUid.String(),
+				Outcome:    domain.AuditDenied,
+				After:      map[string]any{"family_id": current.FamilyID},
+			})
+			return nil, domain.ErrRefreshTokenReused
+		}
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	// 3. ดึง User ล่าสุด (Claims ต้องเป็นข้อมูลปัจจุบัน)
+	user, err := s.userRepo.GetUserByUID(ctx, current.UserUid.String())
+	if err != nil {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if user.DisabledAt != nil {
+		return nil, domain.ErrUserDisabled
+	}
+	if user.MustChangePassword {
+		return nil, domain.ErrPasswordChangeRequired
+	}
+	// ได้ Role ที่บังคับ MFA หลัง Login ไปแล้ว: ต้อง Login ใหม่เพื่อลงทะเบียน
+	enrolled, required, err := s.mfaStatus(ctx, user.Uid.String(), current.TenantUid)
+	if err != nil {
+		return nil, err
+	}
+	if required && !enrolled {
+		return nil, domain.ErrMFARequired
+	}
+
+	res, err := s.issueTokens(ctx, user, current.TenantUid, current.FamilyID, current)
+	if errors.Is(err, domain.ErrRefreshTokenReused) {
+		// มีอีก Request ใช้ Token นี้ไปก่อนหน้าเสี้ยววินาที ถือว่าเป็น Reuse เหมือนกัน
+		s.revokeFamily(ctx, current)
+	}
+	return res, err
+}
+
+// --- Helper: ออก Access Token + Refresh Token ---
+// ถ้ามี previous แปลว่าเป็นการ Rotate (ปิด Token เก่าพร้อมกันใน Transaction เดียว)
+func (s *authService) issueTokens(ctx context.Context, user *domain.User, tenantID *uuid.UUID, familyID uuid.UUID, previous *domain.RefreshToken) (*port.AuthResponse, error) {
+	// 1. Access Token (JWT อายุสั้น)
+	now := time.Now()
+	claims := accessTokenClaims{
+		UserID:   user.Uid.String(),
+		Username: user.Username,
+		Email:    user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(), // jti ใช้อ้างอิงตอน Logout / Revoke
+			Issuer:    s.issuer,
+			Subject:   user.Uid.String(),
+			Audience:  jwt.ClaimStrings{s.audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTokenTTL)),
+		},
+	}
+	if tenantID != nil {
+		claims.TenantID = tenantID.String()
+	}
+	accessToken, err := s.signToken(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Refresh Token (Opaque random string, เก็บแค่ Hash ใน DB)
+	refreshToken, err := generateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	record := &domain.RefreshToken{
+		UserUid:   user.Uid,
+		FamilyID:  familyID,
+		TenantUid: tenantID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
+	}
+
+	if previous == nil {
+		err = s.refreshTokenRepo.Create(ctx, record)
+	} else {
+		err = s.refreshTokenRepo.Rotate(ctx, previous, record)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &port.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// Logout เพิกถอน Access Token ปัจจุบัน และ Refresh Token Family ที่ส่งมา (ถ้ามี)
+func (s *authService) Logout(ctx context.Context, req *port.LogoutReq) error {
+	if req.TokenID != "" {
+		if err := s.denylist.Deny(ctx, req.TokenID, time.Until(req.ExpiresAt)); err != nil {
+			return err
+		}
+	}
+
+	s.audit.Record(ctx, port.AuditEntry{Action: domain.AuditLogout, TargetType: port.AuditTargetUser, TargetID: req.UserID})
+
+	if req.RefreshToken != "" {
+		current, err := s.refreshTokenRepo.GetByHash(ctx, hashToken(req.RefreshToken))
+		// ไม่บอกว่า Refresh Token ไม่ถูกต้อง เพราะผลลัพธ์ของ Logout คือ Token ใช้ไม่ได้อยู่แล้ว
+		if err == nil && current.UserUid.String() == req.UserID {
+			return s.refreshTokenRepo.RevokeFamily(ctx, current.FamilyID.String())
+		}
+	}
+	return nil
+}
+
+// RevokeAllSessions เพิกถอนทุก Session ของ User (ทั้ง Access Token ที่ออกไปแล้ว และ Refresh Token ทั้งหมด)
+func (s *authService) RevokeAllSessions(ctx context.Context, userID string) error {
+	// Access Token ที่ออกก่อนหน้านี้จะหมดอายุเองภายใน accessTokenTTL จึงเก็บ Marker แค่นั้นพอ
+	if err := s.denylist.RevokeUser(ctx, userID, time.Now(), s.accessTokenTTL); err != nil {
+		return err
+	}
+	if err := s.refreshTokenRepo.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, port.AuditEntry{Action: domain.AuditSessionsRevoke, TargetType: port.AuditTargetUser, TargetID: userID})
+	return nil
+}
+
+// IsTokenRevoked ใช้ใน Middleware ก่อนเช็คสิทธิ์
+func (s *authService) IsTokenRevoked(ctx context.Context, userID string, tokenID string, issuedAt time.Time) (bool, error) {
+	if tokenID != "" {
+		denied, err := s.denylist.IsDenied(ctx, tokenID)
+		if err != nil || denied {
+			return denied, err
+		}
+	}
+
+	revokedAt, err := s.denylist.RevokedBefore(ctx, userID)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		return false, err
 	}
+	// iat มีความละเอียดระดับวินาที: Token ที่ออกในวินาทีเดียวกับการ Revoke ถือว่าโดน Revoke ด้วย
+	return !revokedAt.IsZero() && !issuedAt.After(revokedAt), nil
+}
+
+func (s *authService) IsUserDisabled(ctx context.Context, userID string) (bool, error) {
+	return s.denylist.IsUserDisabled(ctx, userID)
+}
+
+// ChangePassword ยืนยันด้วยรหัสผ่านปัจจุบัน แล้วเพิกถอนทุก Session (Token เก่าใช้ไม่ได้อีก)
+func (s *authService) ChangePassword(ctx context.Context, req *port.ChangePasswordReq) (err error) {
+	var user *domain.User
+	defer func() { s.recordLogin(ctx, domain.AuditPasswordChange, req.Username, user, nil, err) }()
 
-	// 2. Check Password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("invalid credentials")
+	user, err = s.verifyCredentials(ctx, req.Username, req.CurrentPassword, req.IP)
+	if err != nil {
+		return err
+	}
+	if user.DisabledAt != nil {
+		return domain.ErrUserDisabled
 	}
 
-	// 3. Generate JWT
-	claims := jwt.MapClaims{
-		"user_id":  user.Uid,
-		"username": user.Username,
-		"email":    user.Email,
-		"exp":      time.Now().Add(time.Hour * 72).Unix(),
+	verr := &domain.ValidationError{}
+	for _, msg := range s.passwordPolicy.Validate(req.NewPassword, user.Username) {
+		verr.Add("new_password", msg)
+	}
+	if req.NewPassword == req.CurrentPassword {
+		verr.Add("new_password", "must differ from the current password")
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	t, err := token.SignedString([]byte(s.jwtSecret))
+	if err := verr.OrNil(); err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcryptCost)
 	if err != nil {
+		return err
+	}
+	user.Password = string(hashed)
+	user.MustChangePassword = false
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	s.throttle.reset(ctx, user.Username, req.IP)
+	return s.RevokeAllSessions(ctx, user.Uid.String())
+}
+
+func (s *authService) UnlockAccount(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetUserByUID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.throttle.unlock(ctx, user); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, port.AuditEntry{Action: domain.AuditAccountUnlock, TargetType: port.AuditTargetUser, TargetID: userID})
+}
+
+// verifyCredentials ตรวจ Username / Password ผ่าน Throttle
+// ไม่พบ User ก็ยังต้อง bcrypt กับ Dummy Hash ให้เวลาตอบเท่ากับกรณีรหัสผิด และนับเป็นการผิดเหมือนกัน
+// รหัสถูกแล้วไม่ล้าง Counter ที่นี่ ผู้เรียกล้างเองเมื่อทำงานเสร็จจริง (เช่น Login ที่ยังต้องผ่าน MFA ยังไม่ล้าง)
+func (s *authService) verifyCredentials(ctx context.Context, username string, password string, ip string) (*domain.User, error) {
+	if err := s.throttle.check(ctx, username, ip); err != nil {
 		return nil, err
 	}
 
-	return &port.AuthResponse{AccessToken: t}, nil
+	hash := dummyPasswordHash
+	user, err := s.userRepo.GetUserByUsername(ctx, username)
+	if err == nil && user.IsServiceAccount() {
+		err = domain.ErrInvalidCredentials // Service Account ไม่มีรหัสผ่าน ใช้ได้แค่ API Key
+	}
+	if err == nil {
+		hash = []byte(user.Password)
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || err != nil {
+		s.throttle.recordFailure(ctx, username, ip)
+		return nil, domain.ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// signToken เซ็นด้วยกุญแจ Active และใส่ kid ใน Header ให้ฝั่งตรวจเลือกกุญแจได้ถูก
+func (s *authService) signToken(claims jwt.Claims) (string, error) {
+	kid, alg, key := s.keySet.SigningKey()
+	method := jwt.GetSigningMethod(alg)
+	if method == nil {
+		return "", fmt.Errorf("unsupported signing algorithm: %s", alg)
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(key)
+}
+
+func (s *authService) revokeFamily(ctx context.Context, token *domain.RefreshToken) {
+	log.Printf("🚨 Refresh token reuse detected (user=%s family=%s), revoking family", token.UserUid, token.FamilyID)
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, token.FamilyID.String()); err != nil {
+		log.Printf("⚠️ Failed to revoke token family %s: %v", token.FamilyID, err)
+	}
+}
+
+// recordLogin บันทึก Event ที่ยืนยันตัวตนด้วยตัวเอง (ยังไม่มี Actor ใน ctx): Actor = User ที่ตรวจผ่านแล้ว (ถ้ามี)
+// ถูกล็อก = denied, ผิดอย่างอื่น = failure
+func (s *authService) recordLogin(ctx context.Context, action string, username string, user *domain.User, res *port.AuthResponse, err error) {
+	entry := port.AuditEntry{Action: action, TargetType: port.AuditTargetUser}
+	details := map[string]any{}
+	if username != "" {
+		details["username"] = username
+	}
+	if user != nil {
+		entry.TargetID = user.Uid.String()
+		entry.ActorID = entry.TargetID
+	}
+	if res != nil && res.MFARequired {
+		details["mfa_challenge"] = true
+	}
+
+	switch {
+	case err == nil:
+	case errors.Is(err, domain.ErrTooManyLoginAttempts):
+		entry.Outcome = domain.AuditDenied
+		details["error"] = err.Error()
+	default:
+		entry.Outcome = domain.AuditFailure
+		details["error"] = err.Error()
+	}
+	if len(details) > 0 {
+		entry.After = details
+	}
+	s.audit.Record(ctx, entry)
+}
+
+// generateOpaqueToken สุ่ม Token 32 bytes (base64url)
+func generateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken ใช้ SHA-256 พอ เพราะ Token สุ่มมาแล้วมี Entropy สูง (ไม่ต้องใช้ bcrypt)
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
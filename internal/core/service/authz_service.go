@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/google/uuid"
+)
+
+type authzService struct {
+	rbacSvc      port.RBACService
+	verifier     port.TokenVerifier
+	authSvc      port.AuthService
+	maxBatchSize int
+}
+
+func NewAuthzService(rbacSvc port.RBACService, verifier port.TokenVerifier, authSvc port.AuthService, cfg config.AuthzConfig) port.AuthzService {
+	return &authzService{
+		rbacSvc:      rbacSvc,
+		verifier:     verifier,
+		authSvc:      authSvc,
+		maxBatchSize: cfg.MaxBatchSize,
+	}
+}
+
+func (s *authzService) Check(ctx context.Context, req *port.AuthzCheckReq) (*port.AuthzResult, error) {
+	batch, err := s.CheckBatch(ctx, &port.AuthzBatchReq{
+		Subject: req.Subject,
+		Checks:  []port.AuthzCheckItem{{Permission: req.Permission, Resource: req.Resource}},
+		Context: req.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &batch.Results[0], nil
+}
+
+func (s *authzService) CheckBatch(ctx context.Context, req *port.AuthzBatchReq) (*port.AuthzBatchResult, error) {
+	if len(req.Checks) == 0 {
+		return nil, fmt.Errorf("%w: checks is empty", domain.ErrInvalidAuthzRequest)
+	}
+	if s.maxBatchSize > 0 && len(req.Checks) > s.maxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d checks per request", domain.ErrInvalidAuthzRequest, s.maxBatchSize)
+	}
+
+	results := make([]port.AuthzResult, len(req.Checks))
+	for i, item := range req.Checks {
+		if item.Resource != nil && (item.Resource.Type == "" || item.Resource.ID == "") {
+			return nil, fmt.Errorf("%w: checks[%d].resource requires type and id", domain.ErrInvalidAuthzRequest, i)
+		}
+		results[i] = port.AuthzResult{Permission: item.Permission, Resource: item.Resource}
+	}
+
+	// 1. หา User จาก Subject (ถ้าไม่ผ่าน ทุกรายการเป็น Deny ด้วยเหตุผลเดียวกัน)
+	userID, ctx, reason, err := s.resolveSubject(ctx, req.Subject)
+	if err != nil {
+		if reason == "" {
+			return nil, err // Error ของระบบ (DB / Redis)
+		}
+		for i := range results {
+			results[i].Reason = reason
+			results[i].Detail = err.Error()
+		}
+		return &port.AuthzBatchResult{Results: results}, nil
+	}
+
+	ctx = port.WithAttributes(ctx, port.AccessAttributes{
+		IP:            req.Context.IP,
+		Time:          time.Now(),
+		ResourceOwner: req.Context.ResourceOwner,
+	})
+
+	// 2. ตัดสินทีละรายการ
+	for i, item := range req.Checks {
+		if err := domain.ValidatePermissionName(item.Permission); err != nil {
+			results[i].Reason = port.AuthzReasonInvalidPermission
+			results[i].Detail = err.Error()
+			continue
+		}
+
+		decision, err := s.rbacSvc.Decide(ctx, userID, item.Permission, item.Resource)
+		if err != nil {
+			return nil, err
+		}
+		results[i].Allowed = decision.Allowed
+		results[i].Reason = decision.Reason
+	}
+
+	return &port.AuthzBatchResult{Results: results}, nil
+}
+
+// resolveSubject คืน userID และ ctx ที่มี Tenant แล้ว
+// reason ไม่ว่าง = Subject ใช้ไม่ได้ (ตอบเป็น Deny) / reason ว่างแต่มี err = Error ของระบบ
+func (s *authzService) resolveSubject(ctx context.Context, subject port.AuthzSubject) (string, context.Context, string, error) {
+	userID := subject.UserID
+	tenantID := subject.TenantID
+	tokenTenant := false
+
+	switch {
+	case subject.Token != "" && subject.UserID != "":
+		return "", ctx, port.AuthzReasonInvalidSubject, errors.New("subject must have either user_id or token, not both")
+
+	case subject.Token != "":
+		// ตรวจ Token แบบเดียวกับ Middleware (Signature, Claims, Denylist)
+		claims, err := s.verifier.Verify(subject.Token)
+		if err != nil {
+			return "", ctx, port.AuthzReasonInvalidSubject, err
+		}
+		revoked, err := s.authSvc.IsTokenRevoked(ctx, claims.UserID, claims.TokenID, claims.IssuedAt)
+		if err != nil {
+			return "", ctx, "", err
+		}
+		if revoked {
+			return "", ctx, port.AuthzReasonInvalidSubject, domain.ErrTokenRevoked
+		}
+		userID = claims.UserID
+
+		// Token ผูก Tenant ไว้แล้ว (เป็นสมาชิกตั้งแต่ตอน Login)
+		if claims.TenantID != "" {
+			if tenantID != "" && tenantID != claims.TenantID {
+				return "", ctx, port.AuthzReasonTenantMismatch, domain.ErrTenantMismatch
+			}
+			tenantID = claims.TenantID
+			tokenTenant = true
+		}
+
+	case subject.UserID == "":
+		return "", ctx, port.AuthzReasonInvalidSubject, errors.New("subject requires user_id or token")
+
+	default:
+		if _, err := uuid.Parse(subject.UserID); err != nil {
+			return "", ctx, port.AuthzReasonInvalidSubject, errors.New("user_id must be a uuid")
+		}
+	}
+
+	// ระงับ / ลบบัญชีแล้วต้องได้ Deny เหมือนที่ Middleware ปฏิเสธ (ทั้งแบบ Token และ user_id)
+	disabled, err := s.authSvc.IsUserDisabled(ctx, userID)
+	if err != nil {
+		return "", ctx, "", err
+	}
+	if disabled {
+		return "", ctx, port.AuthzReasonUserDisabled, domain.ErrUserDisabled
+	}
+
+	if tenantID != "" && !tokenTenant {
+		member, err := s.rbacSvc.IsTenantMember(ctx, tenantID, userID)
+		if err != nil {
+			return "", ctx, "", err
+		}
+		if !member {
+			return "", ctx, port.AuthzReasonNotTenantMember, domain.ErrNotTenantMember
+		}
+	}
+	return userID, port.WithTenant(ctx, tenantID), "", nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/google/uuid"
+)
+
+// Fake ที่ Implement เฉพาะ Method ที่ Decision API ใช้ (ที่เหลือ Panic เพราะ Embed Interface ไว้เฉยๆ)
+type fakeAuthzVerifier struct{ userID string }
+
+func (f fakeAuthzVerifier) Verify(string) (*port.AccessClaims, error) {
+	return &port.AccessClaims{TokenID: "jti-1", UserID: f.userID, IssuedAt: time.Now()}, nil
+}
+
+type fakeAuthzAuthService struct {
+	port.AuthService
+	disabled map[string]bool
+}
+
+func (f *fakeAuthzAuthService) IsTokenRevoked(context.Context, string, string, time.Time) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeAuthzAuthService) IsUserDisabled(_ context.Context, userID string) (bool, error) {
+	return f.disabled[userID], nil
+}
+
+// fakeAuthzRBAC ให้สิทธิ์ทุกอย่าง (ผลที่ได้ Deny จึงมาจากการตรวจ Subject เท่านั้น)
+type fakeAuthzRBAC struct{ port.RBACService }
+
+func (fakeAuthzRBAC) Decide(context.Context, string, string, *port.Resource) (port.Decision, error) {
+	return port.Decision{Allowed: true, Reason: port.ReasonGranted}, nil
+}
+
+func TestAuthzCheckRejectsDisabledUser(t *testing.T) {
+	userID := uuid.NewString()
+	authSvc := &fakeAuthzAuthService{disabled: map[string]bool{}}
+	svc := NewAuthzService(fakeAuthzRBAC{}, fakeAuthzVerifier{userID: userID}, authSvc, config.AuthzConfig{})
+
+	subjects := map[string]port.AuthzSubject{
+		"token":   {Token: "access-token"},
+		"user_id": {UserID: userID},
+	}
+
+	for name, subject := range subjects {
+		t.Run(name, func(t *testing.T) {
+			authSvc.disabled[userID] = false
+			res, err := svc.Check(context.Background(), &port.AuthzCheckReq{Subject: subject, Permission: "report:view"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !res.Allowed {
+				t.Fatalf("active user: allowed = false (%s), want true", res.Reason)
+			}
+
+			authSvc.disabled[userID] = true
+			res, err = svc.Check(context.Background(), &port.AuthzCheckReq{Subject: subject, Permission: "report:view"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Allowed || res.Reason != port.AuthzReasonUserDisabled {
+				t.Errorf("disabled user: allowed = %v, reason = %q, want false / %q", res.Allowed, res.Reason, port.AuthzReasonUserDisabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+type clientAuthenticator struct {
+	secrets map[string][]byte // client id -> sha256(secret)
+}
+
+// NewClientAuthenticator โหลด Client ที่เรียก Decision API ได้จาก Config
+// Secret ของ Service สุ่มมายาวพอ จึงเก็บแค่ SHA-256 (ตรวจเร็ว ไม่ต้องใช้ bcrypt ทุก Request)
+func NewClientAuthenticator(cfg config.AuthzConfig) (port.ClientAuthenticator, error) {
+	secrets := make(map[string][]byte, len(cfg.Clients))
+	for _, c := range cfg.Clients {
+		hash, err := hex.DecodeString(strings.TrimSpace(c.SecretSHA256))
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("authz client %q: secret_sha256 must be 64 hex characters", c.ID)
+		}
+		if c.ID == "" {
+			return nil, fmt.Errorf("authz client id is required")
+		}
+		secrets[c.ID] = hash
+	}
+	if len(secrets) == 0 {
+		log.Println("⚠️ No authz clients configured: /api/authz is unreachable")
+	}
+	return &clientAuthenticator{secrets: secrets}, nil
+}
+
+func (a *clientAuthenticator) Authenticate(clientID string, secret string) error {
+	sum := sha256.Sum256([]byte(secret))
+	expected, ok := a.secrets[clientID]
+	if !ok {
+		// เทียบกับค่าหลอก เพื่อไม่ให้เวลาตอบบอกได้ว่ามี Client นี้หรือไม่
+		expected = make([]byte, sha256.Size)
+	}
+	if subtle.ConstantTimeCompare(sum[:], expected) != 1 || !ok {
+		return domain.ErrInvalidClientCredentials
+	}
+	return nil
+}
@@ -0,0 +1,465 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+// --- Condition Language ---
+// ภาษาเล็กๆ สำหรับเงื่อนไขบน Role-Permission (ไม่มี Loop / ไม่เรียก Function ภายนอก จึงปลอดภัย)
+//
+//	expr       := and ( "||" and )*
+//	and        := unary ( "&&" unary )*
+//	unary      := "!" unary | "(" expr ")" | comparison
+//	comparison := attribute ( "==" | "!=" ) ( attribute | "string" )
+//	            | attribute "in" "string"
+//
+// Attribute: subject, ip, time, weekday, resource.owner, resource.type, resource.id
+//
+//	time in "09:00-18:00"                  ช่วงเวลา (ข้ามเที่ยงคืนได้ เช่น "22:00-06:00" แต่ต้นกับปลายห้ามเท่ากัน)
+//	weekday in "mon-fri"                   วันในสัปดาห์ (ช่วง หรือคั่นด้วย , เช่น "sat,sun")
+//
+// time / weekday ตีความเป็น UTC เสมอ ไม่ขึ้นกับ Timezone ของ Server (เวลาไทย 09:00-18:00 ต้องเขียน "02:00-11:00")
+//	ip in "10.0.0.0/8,192.168.1.0/24"      CIDR (คั่นด้วย ,)
+//	resource.owner == subject              เจ้าของ Resource คือคนที่ขอ
+//
+// ประเมินแบบ 3 ค่า (Kleene): Attribute ที่ไม่มีข้อมูลได้ unknown และ ! ของ unknown ยังเป็น unknown
+// ให้สิทธิ์เฉพาะเมื่อผลเป็น true เท่านั้น (เช่น !(ip in "...") ไม่ผ่านถ้าไม่รู้ IP)
+
+type condition interface {
+	eval(subject string, attrs port.AccessAttributes) truth
+}
+
+type truth int
+
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown
+)
+
+func truthOf(b bool) truth {
+	if b {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+// conditionMet ผ่านเฉพาะเมื่อรู้แน่ว่าเป็นจริง (unknown = ไม่ผ่าน)
+func conditionMet(c condition, subject string, attrs port.AccessAttributes) bool {
+	return c.eval(subject, attrs) == truthTrue
+}
+
+// compileCondition แปลงข้อความเป็น condition ที่พร้อมใช้ (ตรวจ Syntax และค่าคงที่ทั้งหมดตอนนี้เลย)
+func compileCondition(src string) (condition, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &conditionParser{tokens: tokens}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	return cond, nil
+}
+
+// --- AST ---
+
+type orCond struct{ left, right condition }
+type andCond struct{ left, right condition }
+type notCond struct{ inner condition }
+
+// orCond: true ชนะ unknown
+func (c orCond) eval(subject string, a port.AccessAttributes) truth {
+	left, right := c.left.eval(subject, a), c.right.eval(subject, a)
+	switch {
+	case left == truthTrue || right == truthTrue:
+		return truthTrue
+	case left == truthUnknown || right == truthUnknown:
+		return truthUnknown
+	}
+	return truthFalse
+}
+
+// andCond: false ชนะ unknown
+func (c andCond) eval(subject string, a port.AccessAttributes) truth {
+	left, right := c.left.eval(subject, a), c.right.eval(subject, a)
+	switch {
+	case left == truthFalse || right == truthFalse:
+		return truthFalse
+	case left == truthUnknown || right == truthUnknown:
+		return truthUnknown
+	}
+	return truthTrue
+}
+
+func (c notCond) eval(subject string, a port.AccessAttributes) truth {
+	switch c.inner.eval(subject, a) {
+	case truthTrue:
+		return truthFalse
+	case truthFalse:
+		return truthTrue
+	}
+	return truthUnknown
+}
+
+// equalsCond: attribute == attribute / "literal" (ค่าว่าง = unknown ทั้ง == และ !=)
+type equalsCond struct {
+	left   string
+	right  string // attribute หรือ literal (ดู rightIsAttr)
+	negate bool
+
+	rightIsAttr bool
+}
+
+func (c equalsCond) eval(subject string, a port.AccessAttributes) truth {
+	left := attributeValue(c.left, subject, a)
+	right := c.right
+	if c.rightIsAttr {
+		right = attributeValue(c.right, subject, a)
+	}
+	if left == "" || right == "" {
+		return truthUnknown
+	}
+	return truthOf((left == right) != c.negate)
+}
+
+type ipInCond struct{ networks []*net.IPNet }
+
+func (c ipInCond) eval(_ string, a port.AccessAttributes) truth {
+	ip := net.ParseIP(a.IP)
+	if ip == nil {
+		return truthUnknown
+	}
+	for _, n := range c.networks {
+		if n.Contains(ip) {
+			return truthTrue
+		}
+	}
+	return truthFalse
+}
+
+// timeInCond: นาทีนับจากเที่ยงคืน (UTC) [from, to)
+type timeInCond struct{ from, to int }
+
+func (c timeInCond) eval(_ string, a port.AccessAttributes) truth {
+	if a.Time.IsZero() {
+		return truthUnknown
+	}
+	t := a.Time.UTC()
+	m := t.Hour()*60 + t.Minute()
+	if c.from <= c.to {
+		return truthOf(m >= c.from && m < c.to)
+	}
+	return truthOf(m >= c.from || m < c.to) // ข้ามเที่ยงคืน
+}
+
+type weekdayInCond struct{ days map[time.Weekday]bool }
+
+func (c weekdayInCond) eval(_ string, a port.AccessAttributes) truth {
+	if a.Time.IsZero() {
+		return truthUnknown
+	}
+	return truthOf(c.days[a.Time.UTC().Weekday()])
+}
+
+var conditionAttributes = map[string]bool{
+	"subject":        true,
+	"ip":             true,
+	"time":           true,
+	"weekday":        true,
+	"resource.owner": true,
+	"resource.type":  true,
+	"resource.id":    true,
+}
+
+func attributeValue(name string, subject string, a port.AccessAttributes) string {
+	switch name {
+	case "subject":
+		return subject
+	case "ip":
+		return a.IP
+	case "resource.owner":
+		return a.ResourceOwner
+	case "resource.type":
+		return a.ResourceType
+	case "resource.id":
+		return a.ResourceID
+	}
+	return ""
+}
+
+// --- Tokenizer ---
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		ch := rune(src[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case ch == '"':
+			end := strings.IndexByte(src[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, token{tokString, src[i+1 : i+1+end]})
+			i += end + 2
+		case strings.HasPrefix(src[i:], "&&"), strings.HasPrefix(src[i:], "||"),
+			strings.HasPrefix(src[i:], "=="), strings.HasPrefix(src[i:], "!="):
+			tokens = append(tokens, token{tokOp, src[i : i+2]})
+			i += 2
+		case ch == '!' || ch == '(' || ch == ')':
+			tokens = append(tokens, token{tokOp, string(ch)})
+			i++
+		case unicode.IsLetter(ch):
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || src[i] == '.' || src[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, src[start:i]})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", ch, i)
+		}
+	}
+	return tokens, nil
+}
+
+// --- Parser (Recursive Descent) ---
+
+type conditionParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *conditionParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *conditionParser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *conditionParser) next() (token, error) {
+	if p.done() {
+		return token{}, fmt.Errorf("unexpected end of condition")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *conditionParser) acceptOp(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orCond{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (condition, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andCond{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (condition, error) {
+	if p.acceptOp("!") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notCond{inner}, nil
+	}
+	if p.acceptOp("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptOp(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (condition, error) {
+	attr, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if attr.kind != tokIdent || !conditionAttributes[attr.text] {
+		return nil, fmt.Errorf("unknown attribute %q", attr.text)
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case op.kind == tokIdent && op.text == "in":
+		value, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if value.kind != tokString {
+			return nil, fmt.Errorf("%s in: expected a quoted string", attr.text)
+		}
+		return compileIn(attr.text, value.text)
+
+	case op.kind == tokOp && (op.text == "==" || op.text == "!="):
+		value, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		cond := equalsCond{left: attr.text, right: value.text, negate: op.text == "!="}
+		switch value.kind {
+		case tokIdent:
+			if !conditionAttributes[value.text] {
+				return nil, fmt.Errorf("unknown attribute %q", value.text)
+			}
+			cond.rightIsAttr = true
+		case tokString:
+		default:
+			return nil, fmt.Errorf("unexpected %q", value.text)
+		}
+		if attr.text == "time" || attr.text == "weekday" || value.text == "time" || value.text == "weekday" {
+			return nil, fmt.Errorf("%s can only be used with in", attr.text)
+		}
+		return cond, nil
+	}
+
+	return nil, fmt.Errorf("unexpected operator %q", op.text)
+}
+
+func compileIn(attr string, value string) (condition, error) {
+	switch attr {
+	case "ip":
+		var networks []*net.IPNet
+		for _, part := range strings.Split(value, ",") {
+			_, network, err := net.ParseCIDR(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("ip in: %w", err)
+			}
+			networks = append(networks, network)
+		}
+		return ipInCond{networks}, nil
+
+	case "time":
+		fromStr, toStr, ok := strings.Cut(value, "-")
+		if !ok {
+			return nil, fmt.Errorf("time in: expected \"HH:MM-HH:MM\"")
+		}
+		from, err := parseClock(fromStr)
+		if err != nil {
+			return nil, err
+		}
+		to, err := parseClock(toStr)
+		if err != nil {
+			return nil, err
+		}
+		// "09:00-09:00" เป็นช่วงว่าง (ไม่มีวันผ่าน) ถ้าตั้งใจทั้งวันให้ใช้ "00:00-24:00"
+		if from == to || (from == 24*60 && to == 0) {
+			return nil, fmt.Errorf("time in: empty range %q", value)
+		}
+		return timeInCond{from, to}, nil
+
+	case "weekday":
+		days := make(map[time.Weekday]bool)
+		for _, part := range strings.Split(value, ",") {
+			fromStr, toStr, isRange := strings.Cut(strings.TrimSpace(part), "-")
+			from, err := parseWeekday(fromStr)
+			if err != nil {
+				return nil, err
+			}
+			to := from
+			if isRange {
+				if to, err = parseWeekday(toStr); err != nil {
+					return nil, err
+				}
+			}
+			for d := from; ; d = (d + 1) % 7 {
+				days[d] = true
+				if d == to {
+					break
+				}
+			}
+		}
+		return weekdayInCond{days}, nil
+	}
+
+	return nil, fmt.Errorf("%s does not support in", attr)
+}
+
+func parseClock(s string) (int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(s), ":")
+	h, errH := strconv.Atoi(hh)
+	m, errM := strconv.Atoi(mm)
+	if !ok || errH != nil || errM != nil || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q (expected HH:MM)", s)
+	}
+	return h*60 + m, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	d, ok := weekdays[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return 0, fmt.Errorf("invalid weekday %q", s)
+	}
+	return d, nil
+}
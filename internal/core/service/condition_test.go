@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+func TestCompileConditionRejectsInvalid(t *testing.T) {
+	invalid := []string{
+		``,
+		`subject`,
+		`unknown == "x"`,
+		`ip in 10.0.0.0/8`,
+		`ip in "not-a-cidr"`,
+		`time in "9-18"`,
+		`time in "25:00-26:00"`,
+		`time in "09:00-09:00"`,
+		`time in "24:00-00:00"`,
+		`weekday in "someday"`,
+		`time == "09:00"`,
+		`subject == nobody`,
+		`(ip in "10.0.0.0/8"`,
+		`subject == "a" extra`,
+		`resource.type in "x"`,
+		`subject == "unterminated`,
+	}
+	for _, src := range invalid {
+		t.Run(src, func(t *testing.T) {
+			if _, err := compileCondition(src); err == nil {
+				t.Errorf("compileCondition(%q) = nil error, want error", src)
+			}
+		})
+	}
+}
+
+func TestConditionEval(t *testing.T) {
+	const alice = "alice-uid"
+	// 2024-01-03 เป็นวันพุธ
+	office := port.AccessAttributes{
+		IP:            "10.1.2.3",
+		Time:          time.Date(2024, 1, 3, 10, 30, 0, 0, time.UTC),
+		ResourceOwner: alice,
+		ResourceType:  "document",
+		ResourceID:    "doc-1",
+	}
+	night := port.AccessAttributes{IP: "203.0.113.9", Time: time.Date(2024, 1, 6, 23, 15, 0, 0, time.UTC)}
+	// เสาร์ 03:00 เวลาไทย = ศุกร์ 20:00 UTC (time / weekday นับเป็น UTC)
+	bangkok := port.AccessAttributes{Time: time.Date(2024, 1, 6, 3, 0, 0, 0, time.FixedZone("ICT", 7*60*60))}
+	missing := port.AccessAttributes{}
+
+	tests := []struct {
+		src   string
+		attrs port.AccessAttributes
+		want  bool
+	}{
+		{`ip in "10.0.0.0/8"`, office, true},
+		{`ip in "10.0.0.0/8, 192.168.0.0/16"`, night, false},
+		{`time in "09:00-18:00"`, office, true},
+		{`time in "09:00-18:00"`, night, false},
+		{`time in "22:00-06:00"`, night, true},
+		{`weekday in "mon-fri"`, office, true},
+		{`weekday in "mon-fri"`, night, false},
+		{`weekday in "sat,sun"`, night, true},
+		{`weekday in "fri-mon"`, night, true},
+		{`time in "00:00-24:00"`, night, true},
+		{`time in "19:00-21:00"`, bangkok, true},
+		{`time in "02:00-04:00"`, bangkok, false},
+		{`weekday in "fri"`, bangkok, true},
+		{`weekday in "sat"`, bangkok, false},
+		{`resource.owner == subject`, office, true},
+		{`resource.owner != subject`, office, false},
+		{`resource.type == "document" && resource.id == "doc-1"`, office, true},
+		{`resource.type == "project" || ip in "10.0.0.0/8"`, office, true},
+		{`!(ip in "10.0.0.0/8")`, night, true},
+		{`!(ip in "10.0.0.0/8")`, office, false},
+		{`!!(time in "09:00-18:00")`, office, true},
+
+		// ไม่มีข้อมูล = ไม่ผ่าน ไม่ว่าจะอยู่ใต้ ! กี่ชั้น
+		{`ip in "10.0.0.0/8"`, missing, false},
+		{`!(ip in "10.0.0.0/8")`, missing, false},
+		{`!!(ip in "10.0.0.0/8")`, missing, false},
+		{`resource.owner == subject`, missing, false},
+		{`!(resource.owner == subject)`, missing, false},
+		{`resource.owner != subject`, missing, false},
+		{`!(time in "09:00-18:00")`, missing, false},
+		{`!(weekday in "sat,sun")`, missing, false},
+		{`!(ip in "10.0.0.0/8") && subject == "alice-uid"`, missing, false},
+
+		// true ชนะ unknown ใน ||, false ชนะ unknown ใน &&
+		{`ip in "10.0.0.0/8" || subject == "alice-uid"`, missing, true},
+		{`!(ip in "10.0.0.0/8" && subject == "bob-uid")`, missing, true},
+		{`!(ip in "10.0.0.0/8" || subject == "bob-uid")`, missing, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			cond, err := compileCondition(tt.src)
+			if err != nil {
+				t.Fatalf("compileCondition(%q): %v", tt.src, err)
+			}
+			if got := conditionMet(cond, alice, tt.attrs); got != tt.want {
+				t.Errorf("conditionMet = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// loginThrottle กัน Brute Force: หน่วงเวลาเพิ่มขึ้นเรื่อยๆ ทุกครั้งที่ผิด และล็อกเมื่อผิดครบ
+// นับจาก Username ที่ส่งมา ไม่ว่าจะมีในระบบหรือไม่ (คำตอบจึงไม่บอกว่า Username มีอยู่จริง)
+type loginThrottle struct {
+	store port.LoginAttemptStore
+	cfg   config.LockoutConfig
+}
+
+func newLoginThrottle(store port.LoginAttemptStore, cfg config.LockoutConfig) *loginThrottle {
+	return &loginThrottle{store: store, cfg: cfg}
+}
+
+// check คืน *port.LoginLockedError ถ้าถูกล็อก ไม่งั้นหน่วงเวลาตามจำนวนครั้งที่ผิดก่อนให้ตรวจรหัสผ่าน
+func (t *loginThrottle) check(ctx context.Context, username string, ip string) error {
+	return t.checkKey(ctx, usernameAttemptKey(username), ip)
+}
+
+// checkMFA เหมือน check แต่ใช้ Counter ของ Code (แยกจากรหัสผ่าน นับตาม uid)
+func (t *loginThrottle) checkMFA(ctx context.Context, userID string, ip string) error {
+	return t.checkKey(ctx, mfaAttemptKey(userID), ip)
+}
+
+func (t *loginThrottle) checkKey(ctx context.Context, key string, ip string) error {
+	failures, ttl, err := t.store.Failures(ctx, key)
+	if err != nil {
+		return err
+	}
+	if t.cfg.MaxAttempts > 0 && failures >= t.cfg.MaxAttempts {
+		return &port.LoginLockedError{RetryAfter: ttl}
+	}
+
+	var ipFailures int64
+	if ip != "" {
+		var ipTTL time.Duration
+		ipFailures, ipTTL, err = t.store.Failures(ctx, ipAttemptKey(ip))
+		if err != nil {
+			return err
+		}
+		if t.cfg.MaxAttemptsPerIP > 0 && ipFailures >= t.cfg.MaxAttemptsPerIP {
+			return &port.LoginLockedError{RetryAfter: ipTTL}
+		}
+	}
+
+	return sleepContext(ctx, t.delay(max(failures, ipFailures)))
+}
+
+// delay: BaseDelay * 2^(failures-1) ไม่เกิน MaxDelay
+func (t *loginThrottle) delay(failures int64) time.Duration {
+	if failures <= 0 || t.cfg.BaseDelay <= 0 {
+		return 0
+	}
+	d := t.cfg.BaseDelay
+	for i := int64(1); i < failures && d < t.cfg.MaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, t.cfg.MaxDelay)
+}
+
+func (t *loginThrottle) recordFailure(ctx context.Context, username string, ip string) {
+	t.record(ctx, usernameAttemptKey(username), t.cfg.MaxAttempts)
+	if ip != "" {
+		t.record(ctx, ipAttemptKey(ip), t.cfg.MaxAttemptsPerIP)
+	}
+}
+
+func (t *loginThrottle) recordMFAFailure(ctx context.Context, userID string, ip string) {
+	t.record(ctx, mfaAttemptKey(userID), t.cfg.MaxAttempts)
+	if ip != "" {
+		t.record(ctx, ipAttemptKey(ip), t.cfg.MaxAttemptsPerIP)
+	}
+}
+
+func (t *loginThrottle) record(ctx context.Context, key string, limit int64) {
+	count, err := t.store.RecordFailure(ctx, key, t.cfg.Window)
+	if err != nil {
+		log.Printf("⚠️ Failed to record login failure for %s: %v", key, err)
+		return
+	}
+	if limit > 0 && count == limit {
+		log.Printf("🔒 Login locked for %s after %d failed attempts", key, count)
+		if err := t.store.Lock(ctx, key, t.cfg.Duration); err != nil {
+			log.Printf("⚠️ Failed to lock %s: %v", key, err)
+		}
+	}
+}
+
+// reset ตอน Login สำเร็จ ล้างทั้ง Counter ของ Username และ IP
+func (t *loginThrottle) reset(ctx context.Context, username string, ip string) {
+	keys := []string{usernameAttemptKey(username)}
+	if ip != "" {
+		keys = append(keys, ipAttemptKey(ip))
+	}
+	if err := t.store.Reset(ctx, keys...); err != nil {
+		log.Printf("⚠️ Failed to reset login attempts: %v", err)
+	}
+}
+
+// resetMFA ล้างเฉพาะตอนผ่านขั้นที่ 2 แล้ว (รหัสผ่านถูกอย่างเดียวไม่ล้าง กันวน Login ใหม่เพื่อเดา Code ต่อ)
+func (t *loginThrottle) resetMFA(ctx context.Context, userID string) {
+	if err := t.store.Reset(ctx, mfaAttemptKey(userID)); err != nil {
+		log.Printf("⚠️ Failed to reset MFA attempts: %v", err)
+	}
+}
+
+// unlock ปลดทั้ง Counter ของรหัสผ่านและของ Code
+func (t *loginThrottle) unlock(ctx context.Context, user *domain.User) error {
+	return t.store.Reset(ctx, usernameAttemptKey(user.Username), mfaAttemptKey(user.Uid.String()))
+}
+
+// Username ไม่สนตัวพิมพ์เล็ก/ใหญ่ กันการหลบ Counter ด้วย "Alice" / "alice"
+func usernameAttemptKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+func mfaAttemptKey(userID string) string {
+	return "mfa:" + userID
+}
+
+func mfaChallengeAttemptKey(challengeID string) string {
+	return "mfa:challenge:" + challengeID
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dummyPasswordHash ใช้เทียบตอนไม่พบ Username ให้เวลาตอบเท่ากับกรณีรหัสผ่านผิด
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), bcryptCost)
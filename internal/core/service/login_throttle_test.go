@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+)
+
+// fakeAttemptStore นับใน Memory (ไม่มีหมดอายุ)
+type fakeAttemptStore struct{ counts map[string]int64 }
+
+func (f *fakeAttemptStore) Failures(_ context.Context, key string) (int64, time.Duration, error) {
+	return f.counts[key], time.Minute, nil
+}
+
+func (f *fakeAttemptStore) RecordFailure(_ context.Context, key string, _ time.Duration) (int64, error) {
+	f.counts[key]++
+	return f.counts[key], nil
+}
+
+func (f *fakeAttemptStore) Lock(context.Context, string, time.Duration) error { return nil }
+
+func (f *fakeAttemptStore) Reset(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(f.counts, key)
+	}
+	return nil
+}
+
+func TestLoginThrottleResetClearsUsernameAndIP(t *testing.T) {
+	store := &fakeAttemptStore{counts: map[string]int64{}}
+	throttle := newLoginThrottle(store, config.LockoutConfig{MaxAttempts: 5, MaxAttemptsPerIP: 50, Window: time.Minute})
+	ctx := context.Background()
+
+	throttle.recordFailure(ctx, "Alice", "203.0.113.7")
+	throttle.recordFailure(ctx, "bob", "203.0.113.7")
+
+	throttle.reset(ctx, "alice", "203.0.113.7")
+
+	if n := store.counts[usernameAttemptKey("alice")]; n != 0 {
+		t.Errorf("username counter = %d after reset, want 0", n)
+	}
+	if n := store.counts[ipAttemptKey("203.0.113.7")]; n != 0 {
+		t.Errorf("ip counter = %d after reset, want 0", n)
+	}
+	if n := store.counts[usernameAttemptKey("bob")]; n != 1 {
+		t.Errorf("other username counter = %d after reset, want 1", n)
+	}
+}
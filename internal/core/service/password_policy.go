@@ -0,0 +1,101 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+// bcryptMaxBytes bcrypt ตัดรหัสผ่านที่ยาวเกิน 72 Byte ทิ้งเงียบๆ (รหัสที่ต่างกันหลัง Byte ที่ 72 จะ Hash ออกมาเหมือนกัน)
+const bcryptMaxBytes = 72
+
+type passwordPolicy struct {
+	cfg    config.PasswordPolicyConfig
+	banned map[string]bool
+}
+
+// NewPasswordPolicy โหลดรายการรหัสผ่านต้องห้ามตอนเริ่มโปรแกรม (ไฟล์หาไม่เจอ = Error)
+func NewPasswordPolicy(cfg config.PasswordPolicyConfig) (port.PasswordPolicy, error) {
+	if cfg.MaxLength <= 0 || cfg.MaxLength > bcryptMaxBytes {
+		cfg.MaxLength = bcryptMaxBytes
+	}
+	if cfg.MinLength > cfg.MaxLength {
+		return nil, fmt.Errorf("password policy: min_length %d exceeds max_length %d", cfg.MinLength, cfg.MaxLength)
+	}
+
+	p := &passwordPolicy{cfg: cfg, banned: make(map[string]bool)}
+	if cfg.BannedPasswordsFile == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(cfg.BannedPasswordsFile)
+	if err != nil {
+		return nil, fmt.Errorf("password policy: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.banned[strings.ToLower(line)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("password policy: %w", err)
+	}
+	return p, nil
+}
+
+func (p *passwordPolicy) Validate(password string, username string) []string {
+	var problems []string
+
+	if n := utf8.RuneCountInString(password); n < p.cfg.MinLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters", p.cfg.MinLength))
+	}
+	if len(password) > p.cfg.MaxLength {
+		problems = append(problems, fmt.Sprintf("must be at most %d bytes", p.cfg.MaxLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.cfg.RequireUpper && !upper {
+		problems = append(problems, "must contain an uppercase letter")
+	}
+	if p.cfg.RequireLower && !lower {
+		problems = append(problems, "must contain a lowercase letter")
+	}
+	if p.cfg.RequireDigit && !digit {
+		problems = append(problems, "must contain a digit")
+	}
+	if p.cfg.RequireSymbol && !symbol {
+		problems = append(problems, "must contain a symbol")
+	}
+
+	lowered := strings.ToLower(password)
+	if p.banned[lowered] {
+		problems = append(problems, "is too common")
+	}
+	if u := strings.ToLower(strings.TrimSpace(username)); u != "" && strings.Contains(lowered, u) {
+		problems = append(problems, "must not contain the username")
+	}
+	return problems
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/mikespook/gorbac/v3"
+)
+
+// layerPermission เป็น gorbac.Permission แบบแบ่งชั้นด้วย ":" (แนวเดียวกับ gorbac.NewLayerPermission)
+// แต่รองรับ Wildcard "*" เพิ่ม: "report:*" ครอบคลุม "report:view" และ "*" ครอบคลุมทุก Permission
+type layerPermission struct {
+	id string
+}
+
+func newPermission(id string) gorbac.Permission[string] {
+	return &layerPermission{id: id}
+}
+
+func (p *layerPermission) ID() string {
+	return p.id
+}
+
+// Match ถูกเรียกโดย gorbac ด้วย p = Permission ที่ Role ถือ, required = Permission ที่ขอ
+func (p *layerPermission) Match(required gorbac.Permission[string]) bool {
+	return domain.PermissionMatches(p.id, required.ID())
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- Policy Sync ระหว่าง Instance ---
+// ทุกครั้งที่แก้ Policy จะเพิ่ม Version กลางใน Redis แล้ว Publish แจ้ง Instance อื่น
+// Instance ที่พลาดข้อความ (เช่น หลุดจาก Redis ชั่วคราว) จะตามทันตอน Reconcile ด้วยการเทียบ Version
+const (
+	policyVersionKey     = "rbac:policy:version"
+	policyChangedChannel = "rbac:policy:changed"
+)
+
+// policyChanged โหลด Policy ใหม่ใน Instance นี้ แล้วแจ้ง Instance อื่น
+// แจ้งไม่สำเร็จแค่ Log ไว้ (ข้อมูลลง DB แล้ว Instance อื่นจะตามทันเมื่อ Redis กลับมา)
+func (s *rbacService) policyChanged(ctx context.Context) error {
+	// Client ตัดการเชื่อมต่อไปแล้วก็ยังต้องแจ้ง
+	ctx = context.WithoutCancel(ctx)
+
+	version, incrErr := s.redis.Incr(ctx, policyVersionKey).Result()
+	if incrErr != nil {
+		log.Printf("⚠️ Failed to bump policy version: %v", incrErr)
+	}
+	if err := s.LoadPolicy(); err != nil {
+		return err
+	}
+	if incrErr == nil {
+		if err := s.redis.Publish(ctx, policyChangedChannel, version).Err(); err != nil {
+			log.Printf("⚠️ Failed to publish policy change (v%d): %v", version, err)
+		}
+	}
+	return nil
+}
+
+func (s *rbacService) SyncPolicy(ctx context.Context) (bool, error) {
+	version, err := s.sharedPolicyVersion(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	synced := s.syncedVersion
+	s.mu.RUnlock()
+	if version == synced {
+		return false, nil
+	}
+	return true, s.LoadPolicy()
+}
+
+// sharedPolicyVersion ไม่มี Key (ยังไม่เคยแก้ไข / Redis ถูกล้าง) = 0
+func (s *rbacService) sharedPolicyVersion(ctx context.Context) (int64, error) {
+	version, err := s.redis.Get(ctx, policyVersionKey).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// StartPolicySync ฟังข้อความ Policy เปลี่ยนจาก Instance อื่น และ Reconcile ทุกๆ interval จนกว่า ctx จะถูกยกเลิก
+// ทั้งสองทางเรียก SyncPolicy ใน Goroutine เดียวกัน (ไม่โหลดซ้อนกัน)
+func StartPolicySync(ctx context.Context, rbacSvc port.RBACService, rdb *redis.Client, interval time.Duration) {
+	sub := rdb.Subscribe(ctx, policyChangedChannel)
+
+	go func() {
+		defer sub.Close()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		messages := sub.Channel()
+
+		syncPolicy := func(reason string) {
+			reloaded, err := rbacSvc.SyncPolicy(ctx)
+			if err != nil {
+				log.Printf("⚠️ Failed to sync RBAC policy (%s): %v", reason, err)
+				return
+			}
+			if reloaded {
+				log.Printf("🔄 RBAC policy reloaded (%s)", reason)
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				if _, err := strconv.ParseInt(msg.Payload, 10, 64); err != nil {
+					log.Printf("⚠️ Ignoring malformed policy change message: %q", msg.Payload)
+					continue
+				}
+				syncPolicy("v" + msg.Payload + " published")
+			case <-ticker.C:
+				syncPolicy("reconcile")
+			}
+		}
+	}()
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+// --- แก้ไข / ลบ / กู้คืน Role และ Permission ---
+// Cache ของ User เก็บแค่ชื่อ Role: เปลี่ยนชื่อ / ลบ / กู้คืน Role ต้องลบ Cache ของคนที่ถือ Role นั้น
+// ส่วน Permission อยู่ใน Policy (Memory) อย่างเดียว แค่ LoadPolicy ก็พอ
+
+func (s *rbacService) UpdateRole(ctx context.Context, roleID string, req *port.UpdateRoleReq) (*domain.Role, error) {
+	role, err := s.roleRepo.GetRoleByUID(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	before := roleAuditView(role)
+
+	renamed := false
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: name is required", domain.ErrInvalidRoleName)
+		}
+		renamed = name != role.Name
+		if renamed {
+			// ต้องหาคนที่ถือ Role ด้วยชื่อเดิม ก่อนชื่อจะเปลี่ยน
+			if err := s.invalidateRoleHolders(ctx, role.Name); err != nil {
+				return nil, err
+			}
+		}
+		role.Name = name
+	}
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.roleRepo.Update(ctx, role); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditRoleUpdate, TargetType: port.AuditTargetRole, TargetID: roleID, Before: before, After: roleAuditView(role)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if renamed {
+		// Commit แล้ว: คืน Role ที่เปลี่ยนชื่อแล้วพร้อม Error ให้ผู้เรียกรู้ว่าลง DB แต่ Cache / Policy อาจยังไม่ตาม
+		// ลบซ้ำหลังเปลี่ยนชื่อ กัน Request ที่เติม Cache ด้วยชื่อเดิมระหว่างทาง
+		return role, errors.Join(s.invalidateRoleHolders(ctx, role.Name), s.policyChanged(ctx))
+	}
+	return role, nil
+}
+
+func (s *rbacService) DeleteRole(ctx context.Context, roleID string, cascade bool) error {
+	role, err := s.roleRepo.GetRoleByUID(ctx, roleID)
+	if err != nil {
+		return err
+	}
+
+	// หาคนที่ถือ Role ก่อนลบ (cascade จะลบ Assignment ทิ้งไปด้วย)
+	if err := s.invalidateRoleHolders(ctx, role.Name); err != nil {
+		return err
+	}
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.roleRepo.Delete(ctx, roleID, cascade); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditRoleDelete, TargetType: port.AuditTargetRole, TargetID: roleID, Before: roleAuditView(role), After: map[string]any{"cascade": cascade}}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🗑️ Role deleted: %s (cascade=%t)", role.Name, cascade)
+	return s.policyChanged(ctx)
+}
+
+func (s *rbacService) RestoreRole(ctx context.Context, roleID string) error {
+	var role *domain.Role
+	err := auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.roleRepo.Restore(ctx, roleID); err != nil {
+			return port.AuditEntry{}, err
+		}
+		var err error
+		if role, err = s.roleRepo.GetRoleByUID(ctx, roleID); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditRoleRestore, TargetType: port.AuditTargetRole, TargetID: roleID, After: roleAuditView(role)}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Assignment ที่ไม่ได้ cascade กลับมาใช้งานได้ทันที
+	if err := s.invalidateRoleHolders(ctx, role.Name); err != nil {
+		return err
+	}
+	return s.policyChanged(ctx)
+}
+
+func (s *rbacService) GetDeletedRoles(ctx context.Context) ([]domain.Role, error) {
+	return s.roleRepo.GetDeleted(ctx)
+}
+
+func (s *rbacService) UpdatePermission(ctx context.Context, permID string, req *port.UpdatePermReq) (*domain.Permission, error) {
+	perm, err := s.permissionRepo.GetPermissionByUID(ctx, permID)
+	if err != nil {
+		return nil, err
+	}
+	before := permissionAuditView(perm)
+
+	renamed := false
+	if req.Name != nil {
+		if err := domain.ValidatePermissionName(*req.Name); err != nil {
+			return nil, err
+		}
+		renamed = *req.Name != perm.Name
+		perm.Name = *req.Name
+	}
+	if req.Description != nil {
+		perm.Description = *req.Description
+	}
+
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.permissionRepo.Update(ctx, perm); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditPermissionUpdate, TargetType: port.AuditTargetPermission, TargetID: permID, Before: before, After: permissionAuditView(perm)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if renamed {
+		// Deny Rule เก็บเป็น Pattern (ข้อความ) จึงไม่ตามชื่อใหม่ไปด้วย
+		return perm, s.policyChanged(ctx)
+	}
+	return perm, nil
+}
+
+func (s *rbacService) DeletePermission(ctx context.Context, permID string, cascade bool) error {
+	perm, err := s.permissionRepo.GetPermissionByUID(ctx, permID)
+	if err != nil {
+		return err
+	}
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.permissionRepo.Delete(ctx, permID, cascade); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditPermissionDelete, TargetType: port.AuditTargetPermission, TargetID: permID, Before: permissionAuditView(perm), After: map[string]any{"cascade": cascade}}, nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.policyChanged(ctx)
+}
+
+func (s *rbacService) RestorePermission(ctx context.Context, permID string) error {
+	err := auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.permissionRepo.Restore(ctx, permID); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditPermissionRestore, TargetType: port.AuditTargetPermission, TargetID: permID}, nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.policyChanged(ctx)
+}
+
+func (s *rbacService) GetDeletedPermissions(ctx context.Context) ([]domain.Permission, error) {
+	return s.permissionRepo.GetDeleted(ctx)
+}
+
+// invalidateRoleHolders ลบ Cache ของทุกคนที่ถือ Role นี้ (Platform / Tenant / Binding)
+func (s *rbacService) invalidateRoleHolders(ctx context.Context, roleName string) error {
+	rows, err := s.roleRepo.GetRoleHolders(ctx, []string{roleName}, port.RoleHolderFilter{})
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(rows))
+	for _, row := range rows {
+		switch row.Scope {
+		case port.RoleOriginTenant:
+			if row.TenantUid != nil {
+				keys = append(keys, userRolesCacheKey(*row.TenantUid, row.UserUid))
+			}
+		case port.RoleOriginBinding:
+			keys = append(keys, userBindingsCacheKey(row.UserUid))
+		default:
+			keys = append(keys, userRolesCacheKey("", row.UserUid))
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := s.redis.Del(ctx, keys...).Err(); err != nil {
+		log.Printf("⚠️ Failed to invalidate cache of role %s holders: %v", roleName, err)
+	}
+	return nil
+}
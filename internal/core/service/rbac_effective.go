@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+// GetEffectivePermissions ไล่ Role ของ User + Parent ทุกชั้นจาก Policy ใน Memory (ไม่ยิง DB นอกจากดึง Role)
+// Permission ที่โดน Deny ครอบทั้งหมดจะถูกตัดออก ส่วน Deny ที่แคบกว่าจะอยู่ใน Except
+func (s *rbacService) GetEffectivePermissions(ctx context.Context, userID string) (*port.EffectivePermissions, error) {
+	roles, err := s.getUserRolesWithCache(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byPerm := make(map[string]*port.EffectivePermission)
+	add := func(perm string, source port.PermissionSource) {
+		ep, ok := byPerm[perm]
+		if !ok {
+			ep = &port.EffectivePermission{Permission: perm}
+			byPerm[perm] = ep
+		}
+		ep.Sources = append(ep.Sources, source)
+	}
+
+	denies := append([]string(nil), s.userDenies[userID]...)
+	seen := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		if seen[role.Name] {
+			continue
+		}
+		seen[role.Name] = true
+		denies = append(denies, s.roleDenies[role.Name]...)
+
+		s.walkLineage(role.Name, func(path []string) {
+			owner := path[len(path)-1]
+			for _, perm := range s.directPerms[owner] {
+				add(perm, port.PermissionSource{Role: role.Name, Path: path})
+			}
+			for _, g := range s.ownGrants[owner] {
+				add(g.permission, port.PermissionSource{Role: role.Name, Path: path, Condition: g.source})
+			}
+		})
+	}
+
+	result := &port.EffectivePermissions{
+		UserID:        userID,
+		TenantID:      port.TenantFromContext(ctx),
+		PolicyVersion: s.policyVersion,
+		Permissions:   make([]port.EffectivePermission, 0, len(byPerm)),
+	}
+	for perm, ep := range byPerm {
+		if deniedEntirely(perm, denies) {
+			continue
+		}
+		for _, deny := range denies {
+			if domain.PermissionMatches(perm, deny) {
+				ep.Except = appendUnique(ep.Except, deny)
+			}
+		}
+		result.Permissions = append(result.Permissions, *ep)
+	}
+	sort.Slice(result.Permissions, func(i, j int) bool {
+		return result.Permissions[i].Permission < result.Permissions[j].Permission
+	})
+	return result, nil
+}
+
+// deniedEntirely: มี Deny ที่ครอบ Permission นี้ทั้งหมด (Deny เท่ากันหรือกว้างกว่า)
+func deniedEntirely(perm string, denies []string) bool {
+	for _, deny := range denies {
+		if domain.PermissionMatches(deny, perm) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+func (s *rbacService) GetUserRoleNames(ctx context.Context, userID string) ([]string, error) {
+	roles, err := s.getUserRolesWithCache(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool, len(roles))
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		s.walkLineage(role.Name, func(path []string) {
+			name := path[len(path)-1]
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		})
+	}
+	return names, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+// Explain ใช้ decide ตัวเดียวกับ CheckAccess (ผลตรงกันเสมอ)
+// ส่วน Trace ไล่จากสำเนา Policy ที่เก็บไว้ตอน LoadPolicy เพราะ Gorbac บอกไม่ได้ว่าผ่านเพราะ Role ไหน
+func (s *rbacService) Explain(ctx context.Context, userID string, requiredPerm string, resource *port.Resource) (*port.Explanation, error) {
+	roles, attrs, err := s.collectRoles(ctx, userID, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	roleNames := roleNamesOf(roles)
+	exp := &port.Explanation{
+		UserID:         userID,
+		Permission:     requiredPerm,
+		Resource:       resource,
+		TenantID:       port.TenantFromContext(ctx),
+		Decision:       s.decide(userID, roleNames, requiredPerm, attrs),
+		PolicyVersion:  s.policyVersion,
+		PolicyLoadedAt: s.policyLoadedAt,
+		Roles:          roles,
+		Denies:         []port.MatchedDeny{},
+		Grants:         []port.GrantPath{},
+	}
+	if exp.Roles == nil {
+		exp.Roles = []port.ExplainedRole{}
+	}
+
+	for _, pattern := range s.userDenies[userID] {
+		if domain.PermissionMatches(pattern, requiredPerm) {
+			exp.Denies = append(exp.Denies, port.MatchedDeny{Pattern: pattern, Scope: "user"})
+		}
+	}
+
+	seen := make(map[string]bool, len(roleNames))
+	for _, roleName := range roleNames {
+		if seen[roleName] {
+			continue // Role เดียวกันมาจากหลายที่ (เช่น Platform + Binding)
+		}
+		seen[roleName] = true
+
+		s.walkLineage(roleName, func(path []string) {
+			owner := path[len(path)-1]
+			for _, pattern := range s.ownRoleDenies[owner] {
+				if domain.PermissionMatches(pattern, requiredPerm) {
+					exp.Denies = append(exp.Denies, port.MatchedDeny{Pattern: pattern, Scope: "role", Role: roleName, Path: path})
+				}
+			}
+			for _, perm := range s.directPerms[owner] {
+				if domain.PermissionMatches(perm, requiredPerm) {
+					exp.Grants = append(exp.Grants, port.GrantPath{Role: roleName, Path: path, Permission: perm})
+				}
+			}
+			for _, g := range s.ownGrants[owner] {
+				if domain.PermissionMatches(g.permission, requiredPerm) {
+					met := conditionMet(g.cond, userID, attrs)
+					exp.Grants = append(exp.Grants, port.GrantPath{Role: roleName, Path: path, Permission: g.permission, Condition: g.source, ConditionMet: &met})
+				}
+			}
+		})
+	}
+
+	exp.Summary = summarize(exp)
+	return exp, nil
+}
+
+// walkLineage ไล่ Role และบรรพบุรุษทุกชั้น (BFS) ส่ง path จาก Role ต้นทางมาให้ทีละ Role
+func (s *rbacService) walkLineage(roleName string, visit func(path []string)) {
+	visited := map[string]bool{roleName: true}
+	queue := [][]string{{roleName}}
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		visit(path)
+
+		for _, parent := range s.roleParents[path[len(path)-1]] {
+			if visited[parent] {
+				continue
+			}
+			visited[parent] = true
+			next := make([]string, len(path), len(path)+1)
+			copy(next, path)
+			queue = append(queue, append(next, parent))
+		}
+	}
+}
+
+func summarize(exp *port.Explanation) string {
+	switch exp.Reason {
+	case port.ReasonDeniedByUserRule, port.ReasonDeniedByRoleRule:
+		for _, d := range exp.Denies {
+			if d.Scope == "user" {
+				return fmt.Sprintf("denied by user deny rule %q", d.Pattern)
+			}
+			return fmt.Sprintf("denied by deny rule %q via %s", d.Pattern, strings.Join(d.Path, " -> "))
+		}
+
+	case port.ReasonGranted, port.ReasonGrantedByCondition:
+		for _, g := range exp.Grants {
+			if g.ConditionMet == nil || *g.ConditionMet {
+				return fmt.Sprintf("granted by %q via %s", g.Permission, strings.Join(g.Path, " -> "))
+			}
+		}
+
+	case port.ReasonNoMatchingGrant:
+		switch {
+		case len(exp.Roles) == 0:
+			return "user has no active roles in this scope"
+		case len(exp.Grants) == 0:
+			return fmt.Sprintf("none of the %d role(s) or their parents holds a permission matching %q", len(exp.Roles), exp.Permission)
+		default:
+			return "every matching grant is conditional and no condition was met"
+		}
+	}
+	return exp.Reason
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+// GetPermissionHolders หา Role ที่ถือ Permission จาก Policy ใน Memory ก่อน
+// แล้วค่อยดึง User ที่ถือ Role เหล่านั้นจาก DB (Pagination ทำหลังจากจัดกลุ่มตาม User แล้ว)
+func (s *rbacService) GetPermissionHolders(ctx context.Context, query *port.HoldersQuery) (*port.PermissionHolders, error) {
+	if err := domain.ValidatePermissionName(query.Permission); err != nil {
+		return nil, err
+	}
+	switch query.Scope {
+	case "", port.RoleOriginPlatform, port.RoleOriginTenant, port.RoleOriginBinding:
+	default:
+		return nil, fmt.Errorf("%w: unknown scope %q", domain.ErrInvalidQuery, query.Scope)
+	}
+	if query.Page < 1 || query.PageSize < 0 {
+		return nil, fmt.Errorf("%w: page must be >= 1", domain.ErrInvalidQuery)
+	}
+
+	// 1. Role ที่ถือ Permission (+ User ที่โดน Deny ทั้งก้อน) จับ Snapshot ตอนถือ Lock
+	s.mu.RLock()
+	holdingRoles := s.rolesHolding(query.Permission)
+	var denyingRoles []string
+	for _, roleName := range s.roleNames {
+		if deniedEntirely(query.Permission, s.roleDenies[roleName]) {
+			denyingRoles = append(denyingRoles, roleName)
+		}
+	}
+	deniedUsers := make(map[string]bool)
+	for uid, patterns := range s.userDenies {
+		if deniedEntirely(query.Permission, patterns) {
+			deniedUsers[uid] = true
+		}
+	}
+	result := &port.PermissionHolders{
+		Permission:    query.Permission,
+		PolicyVersion: s.policyVersion,
+		Roles:         holdingRoles,
+		Users:         []port.PermissionHolder{},
+		Page:          query.Page,
+		PageSize:      query.PageSize,
+	}
+	s.mu.RUnlock()
+
+	byRole := make(map[string]port.HoldingRole, len(holdingRoles))
+	roleNames := make([]string, 0, len(holdingRoles))
+	for _, hr := range holdingRoles {
+		if query.Role != "" && hr.Role != query.Role {
+			continue
+		}
+		byRole[hr.Role] = hr
+		roleNames = append(roleNames, hr.Role)
+	}
+	if len(roleNames) == 0 {
+		return result, nil
+	}
+
+	// 2. User ที่ถือ Role เหล่านั้น (เรียงตาม username มาจาก DB แล้ว)
+	filter := port.RoleHolderFilter{
+		Search:     query.Search,
+		Scope:      query.Scope,
+		TenantID:   query.TenantID,
+		ActiveOnly: true,
+	}
+	rows, err := s.roleRepo.GetRoleHolders(ctx, roleNames, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deny ของ Role มีผลกับทุก Role ของ User ใน Scope เดียวกัน
+	// (Platform = ทุกที่, Tenant = เฉพาะ Tenant นั้น, Binding = เฉพาะ Resource นั้น)
+	blocked := make(map[string]bool)
+	if len(denyingRoles) > 0 {
+		filter.Scope = ""
+		denyRows, err := s.roleRepo.GetRoleHolders(ctx, denyingRoles, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range denyRows {
+			blocked[holderScopeKey(row)] = true
+		}
+	}
+
+	index := make(map[string]int)
+	for _, row := range rows {
+		if deniedUsers[row.UserUid] || blocked[row.UserUid] || blocked[holderScopeKey(row)] {
+			continue
+		}
+		if row.Scope == port.RoleOriginBinding && blocked[row.UserUid+"|binding|"+row.ResourceType+"|"+domain.WildcardResourceID] {
+			continue
+		}
+		i, ok := index[row.UserUid]
+		if !ok {
+			i = len(result.Users)
+			index[row.UserUid] = i
+			result.Users = append(result.Users, port.PermissionHolder{UserID: row.UserUid, Username: row.Username, Email: row.Email})
+		}
+
+		hr := byRole[row.RoleName]
+		grant := port.HolderGrant{Role: row.RoleName, Scope: row.Scope, Path: hr.Path, Condition: hr.Condition}
+		if row.TenantUid != nil {
+			grant.TenantID = *row.TenantUid
+		}
+		if row.Scope == port.RoleOriginBinding {
+			grant.Resource = &port.Resource{Type: row.ResourceType, ID: row.ResourceID}
+		}
+		result.Users[i].Grants = append(result.Users[i].Grants, grant)
+	}
+
+	// 3. Pagination
+	result.Total = len(result.Users)
+	if query.PageSize > 0 {
+		start := (query.Page - 1) * query.PageSize
+		end := start + query.PageSize
+		if start > result.Total {
+			start = result.Total
+		}
+		if end > result.Total {
+			end = result.Total
+		}
+		result.Users = result.Users[start:end]
+	}
+	return result, nil
+}
+
+// holderScopeKey: Platform ใช้แค่ uid (ครอบทุก Scope ของ User นั้น)
+func holderScopeKey(row port.RoleHolderRow) string {
+	switch row.Scope {
+	case port.RoleOriginTenant:
+		tenant := ""
+		if row.TenantUid != nil {
+			tenant = *row.TenantUid
+		}
+		return row.UserUid + "|tenant|" + tenant
+	case port.RoleOriginBinding:
+		return row.UserUid + "|binding|" + row.ResourceType + "|" + row.ResourceID
+	}
+	return row.UserUid
+}
+
+// rolesHolding Role ทุกตัวที่ได้ Permission นี้ (ตรงๆ หรือผ่าน Parent) และไม่ถูก Deny ของตัวเองครอบไว้
+// ถ้ามีทั้งแบบไม่มีเงื่อนไขและมีเงื่อนไข จะเลือกแบบไม่มีเงื่อนไข (ผู้เรียกต้องถือ s.mu.RLock อยู่)
+func (s *rbacService) rolesHolding(perm string) []port.HoldingRole {
+	holding := []port.HoldingRole{}
+	for _, roleName := range s.roleNames {
+		if deniedEntirely(perm, s.roleDenies[roleName]) {
+			continue
+		}
+
+		var direct, conditional *port.HoldingRole
+		s.walkLineage(roleName, func(path []string) {
+			if direct != nil {
+				return
+			}
+			owner := path[len(path)-1]
+			for _, p := range s.directPerms[owner] {
+				if domain.PermissionMatches(p, perm) {
+					direct = &port.HoldingRole{Role: roleName, Path: path, Permission: p}
+					return
+				}
+			}
+			if conditional != nil {
+				return
+			}
+			for _, g := range s.ownGrants[owner] {
+				if domain.PermissionMatches(g.permission, perm) {
+					conditional = &port.HoldingRole{Role: roleName, Path: path, Permission: g.permission, Condition: g.source}
+					return
+				}
+			}
+		})
+
+		switch {
+		case direct != nil:
+			holding = append(holding, *direct)
+		case conditional != nil:
+			holding = append(holding, *conditional)
+		}
+	}
+	return holding
+}
@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
 	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/google/uuid"
 	"github.com/mikespook/gorbac/v3"
 	"github.com/redis/go-redis/v9"
 )
@@ -18,84 +20,466 @@ type rbacService struct {
 	userRepo       port.UserRepository
 	roleRepo       port.RoleRepository
 	permissionRepo port.PermissionRepository
+	bindingRepo    port.ResourceBindingRepository
+	tenantRepo     port.TenantRepository
+	denyRepo       port.DenyRuleRepository
+	tx             port.Transactor
+	audit          port.AuditLogger
 	rbac           *gorbac.RBAC[string]
 	redis          *redis.Client
-	mu             sync.RWMutex // ใช้ Lock เวลา Reload Policy
+	mu             sync.RWMutex // ใช้ Lock เวลา Swap Policy ชุดใหม่
+	loadMu         sync.Mutex   // ให้ LoadPolicy ทำทีละรอบ
+
+	// Deny Rules (โหลดพร้อม Policy): roleDenies รวม Deny ของ Parent ทุกชั้นไว้แล้ว
+	roleDenies map[string][]string // role name -> permission patterns
+	userDenies map[string][]string // user uid -> permission patterns
+
+	// Conditional Grants (Compile ตอน LoadPolicy): รวมของ Parent ทุกชั้นไว้แล้วเหมือน roleDenies
+	conditionalGrants map[string][]conditionalGrant // role name -> grants
+
+	// สำเนาโครงสร้าง Policy แบบไม่รวม Parent (ใช้ตอบ Explain ว่าสิทธิ์มาจากเส้นทางไหน)
+	roleNames     []string
+	directPerms   map[string][]string           // role name -> permission ที่ไม่มีเงื่อนไข
+	roleParents   map[string][]string           // role name -> parent names
+	ownRoleDenies map[string][]string           // role name -> deny patterns ของ Role นั้นเอง
+	ownGrants     map[string][]conditionalGrant // role name -> conditional grants ของ Role นั้นเอง
+
+	// policyVersion เพิ่มทุกครั้งที่ LoadPolicy สำเร็จ
+	policyVersion  uint64
+	policyLoadedAt time.Time
+	// syncedVersion Version กลางใน Redis ตอนที่โหลด Policy ชุดนี้ (-1 = ไม่รู้ เพราะอ่าน Redis ไม่ได้)
+	syncedVersion int64
+}
+
+// conditionalGrant: Permission ที่มีเงื่อนไขถูกแยกไว้ใน Role สังเคราะห์ของ Gorbac
+// แล้วเช็คผ่าน IsGranted พร้อม Assertion ที่ประเมิน Condition
+type conditionalGrant struct {
+	gorbacRole string
+	cond       condition
+
+	role       string // Role เจ้าของ Grant
+	permission string
+	source     string // ข้อความ Condition ก่อน Compile
+}
+
+// conditionalRoleID ชื่อ Role สังเคราะห์ (มี \x00 นำหน้า จึงไม่ชนกับชื่อ Role จริง)
+func conditionalRoleID(roleName string, permName string) string {
+	return "\x00cond:" + roleName + ":" + permName
 }
 
-func NewRBACService(userRepo port.UserRepository, roleRepo port.RoleRepository, permissionRepo port.PermissionRepository, rdb *redis.Client) port.RBACService {
+func NewRBACService(userRepo port.UserRepository, roleRepo port.RoleRepository, permissionRepo port.PermissionRepository, bindingRepo port.ResourceBindingRepository, tenantRepo port.TenantRepository, denyRepo port.DenyRuleRepository, tx port.Transactor, audit port.AuditLogger, rdb *redis.Client) port.RBACService {
 	return &rbacService{
 		userRepo:       userRepo,
 		roleRepo:       roleRepo,
 		permissionRepo: permissionRepo,
+		bindingRepo:    bindingRepo,
+		tenantRepo:     tenantRepo,
+		denyRepo:       denyRepo,
+		tx:             tx,
+		audit:          audit,
 		rbac:           gorbac.New[string](),
 		redis:          rdb,
+		syncedVersion:  -1,
 	}
 }
 
 func (s *rbacService) LoadPolicy() error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	// โหลดทีละรอบ: กันรอบที่อ่าน DB ก่อนแต่ Swap ทีหลังเขียนทับ Policy ที่ใหม่กว่า
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
 
-	s.rbac = gorbac.New[string]() // เคลียร์ Policy เก่าออกก่อนโหลดใหม่
+	// อ่าน Version ก่อนโหลด: ถ้ามีการแก้ไขระหว่างโหลด Version ที่จำไว้จะเก่ากว่า แล้ว SyncPolicy จะโหลดซ้ำให้เอง
+	version, err := s.sharedPolicyVersion(context.Background())
+	if err != nil {
+		log.Printf("⚠️ Failed to read policy version: %v", err)
+		version = -1
+	}
+
+	// ประกอบ Policy ใหม่ในตัวแปร Local ทั้งหมดก่อน (ไม่ถือ Lock ระหว่างอ่าน DB)
+	// ถ้าพลาดกลางทาง Policy เดิมยังใช้ต่อได้ครบชุด
+	rbac := gorbac.New[string]()
 
 	// 1. ดึงข้อมูล Role + Permission จาก Repository
 	roles, err := s.roleRepo.GetAll(context.Background())
 	if err != nil {
 		return err
 	}
+	userDenyRules, err := s.denyRepo.GetAllUserDenies(context.Background())
+	if err != nil {
+		return err
+	}
 
 	// 2. Load เข้า Gorbac
+	// Permission ที่มี Condition จะไม่ Assign ให้ Role ตรงๆ แต่แยกไปเป็น Role สังเคราะห์
+	ownGrants := make(map[string][]conditionalGrant)
+	directPerms := make(map[string][]string, len(roles))
+	conditionCount := 0
 	for _, r := range roles {
+		conditions := make(map[string]string, len(r.PermissionLinks))
+		for _, link := range r.PermissionLinks {
+			conditions[link.PermissionUid.String()] = link.Condition
+		}
+
 		role := gorbac.NewRole(r.Name)
 		for _, p := range r.Permissions {
-			role.Assign(gorbac.NewPermission(p.Name))
+			src := conditions[p.Uid.String()]
+			if src == "" {
+				role.Assign(newPermission(p.Name))
+				directPerms[r.Name] = append(directPerms[r.Name], p.Name)
+				continue
+			}
+
+			// Compile ไม่ผ่าน = ไม่ให้สิทธิ์นี้เลย (Fail Closed)
+			cond, err := compileCondition(src)
+			if err != nil {
+				fmt.Printf("⚠️ Skipping condition on %s -> %s: %v\n", r.Name, p.Name, err)
+				continue
+			}
+			condRoleID := conditionalRoleID(r.Name, p.Name)
+			condRole := gorbac.NewRole(condRoleID)
+			condRole.Assign(newPermission(p.Name))
+			if err := rbac.Add(condRole); err != nil {
+				fmt.Printf("⚠️ Error adding conditional grant %s -> %s: %v\n", r.Name, p.Name, err)
+				continue
+			}
+			ownGrants[r.Name] = append(ownGrants[r.Name], conditionalGrant{
+				gorbacRole: condRoleID,
+				cond:       cond,
+				role:       r.Name,
+				permission: p.Name,
+				source:     src,
+			})
+			conditionCount++
 		}
-		if err := s.rbac.Add(role); err != nil {
+		if err := rbac.Add(role); err != nil {
 			fmt.Printf("⚠️ Error adding role %s: %v\n", r.Name, err)
 		}
 	}
 
-	fmt.Printf("✅ RBAC Policy Loaded: %d roles\n", len(roles))
+	// 3. ผูก Role Hierarchy (ต้องทำหลัง Add ครบทุก Role เพราะ Parent ต้องมีอยู่ใน Gorbac ก่อน)
+	roleParents := make(map[string][]string, len(roles))
+	for _, r := range roles {
+		for _, p := range r.Parents {
+			roleParents[r.Name] = append(roleParents[r.Name], p.Name)
+		}
+		if len(roleParents[r.Name]) == 0 {
+			continue
+		}
+		if err := rbac.SetParents(r.Name, roleParents[r.Name]); err != nil {
+			fmt.Printf("⚠️ Error setting parents of role %s: %v\n", r.Name, err)
+		}
+	}
+
+	// 4. Deny Rules / Conditional Grants: Role ลูกได้ของ Parent ไปด้วย (เหมือนที่ได้ Permission)
+	ownDenies := make(map[string][]string, len(roles))
+	for _, r := range roles {
+		for _, d := range r.Denies {
+			ownDenies[r.Name] = append(ownDenies[r.Name], d.Permission)
+		}
+	}
+	roleDenies := make(map[string][]string, len(roles))
+	conditionalGrants := make(map[string][]conditionalGrant, len(roles))
+	for name, lineage := range roleLineage(roles) {
+		for _, ancestor := range lineage {
+			roleDenies[name] = append(roleDenies[name], ownDenies[ancestor]...)
+			conditionalGrants[name] = append(conditionalGrants[name], ownGrants[ancestor]...)
+		}
+	}
+	userDenies := make(map[string][]string)
+	for _, d := range userDenyRules {
+		uid := d.UserUid.String()
+		userDenies[uid] = append(userDenies[uid], d.Permission)
+	}
+
+	roleNames := make([]string, 0, len(roles))
+	for _, r := range roles {
+		roleNames = append(roleNames, r.Name)
+	}
+
+	// 5. สลับทั้งชุดพร้อมกัน (ผู้ตรวจสิทธิ์ไม่มีทางเห็น Role ใหม่คู่กับ Deny ชุดเก่า)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rbac = rbac
+	s.roleDenies = roleDenies
+	s.userDenies = userDenies
+	s.conditionalGrants = conditionalGrants
+	s.roleNames = roleNames
+	s.directPerms = directPerms
+	s.roleParents = roleParents
+	s.ownRoleDenies = ownDenies
+	s.ownGrants = ownGrants
+	s.policyVersion++
+	s.policyLoadedAt = time.Now()
+	s.syncedVersion = version
+
+	fmt.Printf("✅ RBAC Policy Loaded (v%d): %d roles, %d conditional grants, %d user deny rules\n", s.policyVersion, len(roles), conditionCount, len(userDenyRules))
 	return nil
 }
 
+// roleLineage คืนชื่อ Role ตัวเอง + บรรพบุรุษทุกชั้นของแต่ละ Role
+// ใช้รวม Deny / Conditional Grant ที่ Role ลูกได้จาก Parent (เหมือนที่ได้ Permission)
+func roleLineage(roles []domain.Role) map[string][]string {
+	parentsOf := make(map[string][]string, len(roles))
+	for _, r := range roles {
+		for _, p := range r.Parents {
+			parentsOf[r.Name] = append(parentsOf[r.Name], p.Name)
+		}
+	}
+
+	result := make(map[string][]string, len(roles))
+	for _, r := range roles {
+		visited := map[string]bool{}
+		stack := []string{r.Name}
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if visited[current] {
+				continue
+			}
+			visited[current] = true
+			result[r.Name] = append(result[r.Name], current)
+			stack = append(stack, parentsOf[current]...)
+		}
+	}
+	return result
+}
+
 // CheckAccess แบบมี Redis Cache
-func (s *rbacService) CheckAccess(userID string, requiredPerm string) (bool, error) {
-	// 1. หาว่า User มี Role อะไรบ้าง (ดึงผ่าน Cache)
-	userRoleNames, err := s.getUserRolesWithCache(userID)
+// ถ้า ctx มี Tenant (port.WithTenant) จะรวม Role ของ Tenant นั้นด้วย
+func (s *rbacService) CheckAccess(ctx context.Context, userID string, requiredPerm string) (bool, error) {
+	decision, err := s.Decide(ctx, userID, requiredPerm, nil)
+	return decision.Allowed, err
+}
+
+// CheckAccessOn เช็คสิทธิ์บน Resource: Role ทั่วไปของ User ใช้ได้กับทุก Resource
+// ส่วน Role ที่ผูกกับ Resource จะใช้ได้เฉพาะ Resource ที่ตรงกัน (หรือ Wildcard "*")
+func (s *rbacService) CheckAccessOn(ctx context.Context, userID string, requiredPerm string, resource port.Resource) (bool, error) {
+	decision, err := s.Decide(ctx, userID, requiredPerm, &resource)
+	return decision.Allowed, err
+}
+
+// Decide คือ CheckAccess / CheckAccessOn ที่บอกเหตุผลด้วย (resource nil = ไม่เจาะจง Resource)
+func (s *rbacService) Decide(ctx context.Context, userID string, requiredPerm string, resource *port.Resource) (port.Decision, error) {
+	roles, attrs, err := s.collectRoles(ctx, userID, resource)
 	if err != nil {
-		return false, err
+		return port.Decision{}, err
 	}
 
+	// 3. ตัดสินรวมทีเดียว (Deny ของ Role ไหนก็ตามชนะ Allow ทั้งหมด)
 	s.mu.RLock()
-	defer s.mu.RUnlock()
+	decision := s.decide(userID, roleNamesOf(roles), requiredPerm, attrs)
+	s.mu.RUnlock()
+
+	if !decision.Allowed {
+		s.audit.RecordDenied(ctx, userID, requiredPerm, resource, decision.Reason)
+	}
+	return decision, nil
+}
+
+// collectRoles รวม Role ทั้งหมดที่ใช้ตัดสิน พร้อมบอกว่ามาจากไหน (Platform / Tenant / Binding, Cache / DB)
+func (s *rbacService) collectRoles(ctx context.Context, userID string, resource *port.Resource) ([]port.ExplainedRole, port.AccessAttributes, error) {
+	attrs := port.AttributesFromContext(ctx)
+
+	// 1. หาว่า User มี Role อะไรบ้าง (Global / Tenant ดึงผ่าน Cache)
+	roles, err := s.getUserRolesWithCache(ctx, userID)
+	if err != nil {
+		return nil, attrs, err
+	}
+
+	if resource != nil {
+		// 2. รวม Role ที่ผูกกับ Resource นี้
+		bindings, fromCache, err := s.getUserBindingsWithCache(ctx, userID)
+		if err != nil {
+			return nil, attrs, err
+		}
+		for _, b := range bindings {
+			if b.matches(*resource) {
+				roles = append(roles, port.ExplainedRole{Name: b.RoleName, Origin: port.RoleOriginBinding, Source: cacheSource(fromCache)})
+			}
+		}
+
+		// resource.type / resource.id ใน Condition มาจาก Resource ที่เช็ค
+		attrs.ResourceType = resource.Type
+		attrs.ResourceID = resource.ID
+	}
+
+	return roles, attrs, nil
+}
+
+func roleNamesOf(roles []port.ExplainedRole) []string {
+	names := make([]string, 0, len(roles))
+	for _, r := range roles {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func cacheSource(fromCache bool) string {
+	if fromCache {
+		return port.RoleSourceCache
+	}
+	return port.RoleSourceDB
+}
 
-	perm := gorbac.NewPermission(requiredPerm)
+// decide: Deny ชนะ Allow เสมอ (ผู้เรียกต้องถือ s.mu.RLock อยู่)
+// สิทธิ์ที่ไม่มีเงื่อนไขเช็คก่อน แล้วค่อยประเมิน Conditional Grant ด้วย attrs ของ Request
+func (s *rbacService) decide(userID string, roleNames []string, requiredPerm string, attrs port.AccessAttributes) port.Decision {
+	if reason, denied := s.isDenied(userID, roleNames, requiredPerm); denied {
+		return port.Decision{Allowed: false, Reason: reason} // ไม่ผ่าน (โดน Deny)
+	}
 
-	// 2. วนลูปเช็คสิทธิ์กับ Gorbac (ใน Memory)
-	for _, roleName := range userRoleNames {
+	perm := newPermission(requiredPerm)
+	for _, roleName := range roleNames {
 		if s.rbac.IsGranted(roleName, perm, nil) {
-			return true, nil // ผ่าน
+			return port.Decision{Allowed: true, Reason: port.ReasonGranted} // ผ่าน
 		}
 	}
 
-	return false, nil // ไม่ผ่าน
+	for _, roleName := range roleNames {
+		for _, grant := range s.conditionalGrants[roleName] {
+			if s.rbac.IsGranted(grant.gorbacRole, perm, grant.assertion(userID, attrs)) {
+				return port.Decision{Allowed: true, Reason: port.ReasonGrantedByCondition} // ผ่าน (เงื่อนไขเป็นจริง)
+			}
+		}
+	}
+
+	return port.Decision{Allowed: false, Reason: port.ReasonNoMatchingGrant} // ไม่ผ่าน
+}
+
+// assertion แปลง Condition เป็น gorbac.AssertionFunc
+// (Gorbac ส่ง ID ของ Role มาให้ ไม่ใช่ User จึงต้องจับ userID ไว้ใน Closure เอง)
+func (g conditionalGrant) assertion(userID string, attrs port.AccessAttributes) gorbac.AssertionFunc[string] {
+	return func(*gorbac.RBAC[string], string, gorbac.Permission[string]) bool {
+		return conditionMet(g.cond, userID, attrs)
+	}
+}
+
+func (s *rbacService) isDenied(userID string, roleNames []string, requiredPerm string) (string, bool) {
+	for _, pattern := range s.userDenies[userID] {
+		if domain.PermissionMatches(pattern, requiredPerm) {
+			return port.ReasonDeniedByUserRule, true
+		}
+	}
+	for _, roleName := range roleNames {
+		for _, pattern := range s.roleDenies[roleName] {
+			if domain.PermissionMatches(pattern, requiredPerm) {
+				return port.ReasonDeniedByRoleRule, true
+			}
+		}
+	}
+	return "", false
+}
+
+// cachedBinding คือ ResourceRoleBinding แบบย่อที่เก็บใน Redis
+type cachedBinding struct {
+	RoleName     string `json:"role"`
+	ResourceType string `json:"type"`
+	ResourceID   string `json:"id"`
+}
+
+func (b cachedBinding) matches(resource port.Resource) bool {
+	if b.ResourceType != resource.Type {
+		return false
+	}
+	return b.ResourceID == domain.WildcardResourceID || b.ResourceID == resource.ID
+}
+
+// --- Helper: ดึง Binding ของ User (Redis -> DB fallback) ---
+// fromCache = true ถ้าได้มาจาก Redis
+func (s *rbacService) getUserBindingsWithCache(ctx context.Context, userID string) ([]cachedBinding, bool, error) {
+	cacheKey := userBindingsCacheKey(userID)
+
+	val, err := s.redis.Get(ctx, cacheKey).Result()
+	if err == nil {
+		var bindings []cachedBinding
+		if err := json.Unmarshal([]byte(val), &bindings); err == nil {
+			return bindings, true, nil
+		}
+	} else if err != redis.Nil {
+		log.Printf("⚠️ Redis error: %v (falling back to DB)", err)
+	}
+
+	rows, err := s.bindingRepo.GetByUserUID(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	bindings := make([]cachedBinding, 0, len(rows))
+	for _, b := range rows {
+		if b.Role == nil {
+			continue
+		}
+		bindings = append(bindings, cachedBinding{RoleName: b.Role.Name, ResourceType: b.ResourceType, ResourceID: b.ResourceID})
+	}
+
+	// เก็บแม้จะว่าง เพราะ User ส่วนใหญ่ไม่มี Binding (กันยิง DB ทุก Request)
+	go func() {
+		encoded, _ := json.Marshal(bindings)
+		if err := s.redis.Set(context.Background(), cacheKey, encoded, time.Hour*1).Err(); err != nil {
+			log.Printf("⚠️ Failed to set cache: %v", err)
+		}
+	}()
+
+	return bindings, false, nil
+}
+
+func userBindingsCacheKey(userID string) string {
+	return fmt.Sprintf("rbac:user:%s:bindings", userID)
 }
 
 // --- Helper: ดึง Role (Redis -> DB fallback) ---
 // Best Practice: แยก Logic การดึง Role ออกมาให้ชัดเจน
-func (s *rbacService) getUserRolesWithCache(userID string) ([]string, error) {
-	ctx := context.Background()
-	cacheKey := fmt.Sprintf("rbac:user:%s:roles", userID)
+// Role ระดับ Platform (user_roles) ใช้ได้ทุก Tenant ส่วน Role ของ Tenant ใช้ได้เฉพาะ Tenant ที่อยู่ใน ctx
+func (s *rbacService) getUserRolesWithCache(ctx context.Context, userID string) ([]port.ExplainedRole, error) {
+	roleNames, fromCache, err := s.loadRolesWithCache(ctx, userRolesCacheKey("", userID), func() ([]domain.Role, *time.Time, error) {
+		roles, err := s.roleRepo.GetRoleByUserUID(ctx, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		next, err := s.roleRepo.GetNextRoleChange(ctx, userID, time.Now())
+		return roles, next, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	roles := make([]port.ExplainedRole, 0, len(roleNames))
+	for _, name := range roleNames {
+		roles = append(roles, port.ExplainedRole{Name: name, Origin: port.RoleOriginPlatform, Source: cacheSource(fromCache)})
+	}
 
+	tenantID := port.TenantFromContext(ctx)
+	if tenantID == "" {
+		return roles, nil
+	}
+
+	tenantRoleNames, fromCache, err := s.loadRolesWithCache(ctx, userRolesCacheKey(tenantID, userID), func() ([]domain.Role, *time.Time, error) {
+		roles, err := s.tenantRepo.GetRolesByTenantAndUser(ctx, tenantID, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		next, err := s.tenantRepo.GetNextRoleChange(ctx, tenantID, userID, time.Now())
+		return roles, next, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range tenantRoleNames {
+		roles = append(roles, port.ExplainedRole{Name: name, Origin: port.RoleOriginTenant, Source: cacheSource(fromCache)})
+	}
+
+	return roles, nil
+}
+
+// load คืน Role ที่ใช้งานได้ตอนนี้ + เวลาถัดไปที่ Assignment จะเปลี่ยน (เริ่ม/หมดอายุ)
+// fromCache = true ถ้าได้มาจาก Redis
+func (s *rbacService) loadRolesWithCache(ctx context.Context, cacheKey string, load func() ([]domain.Role, *time.Time, error)) ([]string, bool, error) {
 	// A. ลองดึงจาก Redis ก่อน (Fail-safe: ถ้า Redis error ให้ข้ามไป DB เลย)
 	val, err := s.redis.Get(ctx, cacheKey).Result()
 	if err == nil {
 		// Cache HIT!
 		var roleNames []string
 		if err := json.Unmarshal([]byte(val), &roleNames); err == nil {
-			return roleNames, nil
+			return roleNames, true, nil
 		}
 	} else if err != redis.Nil {
 		// Redis Error (ไม่ใช่หาไม่เจอ แต่เป็น connection error ฯลฯ)
@@ -103,9 +487,9 @@ func (s *rbacService) getUserRolesWithCache(userID string) ([]string, error) {
 	}
 
 	// B. Cache MISS หรือ Redis ล่ม -> ดึงจาก Database
-	userRoles, err := s.roleRepo.GetRoleByUserUID(ctx, userID)
+	userRoles, nextChange, err := load()
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// แปลง Object Role เป็น List of Strings (เพื่อเก็บใน Redis/Gorbac)
@@ -116,122 +500,570 @@ func (s *rbacService) getUserRolesWithCache(userID string) ([]string, error) {
 
 	// C. บันทึกลง Redis (Background Task)
 	// Best Practice: ตั้ง TTL (เช่น 1 ชั่วโมง) เพื่อกันข้อมูลเก่าค้างตลอดกาล
-	if len(roleNames) > 0 {
+	// และห้ามเกินเวลาที่ Assignment ถัดไปจะเริ่ม/หมดอายุ ไม่งั้น Cache จะยังให้สิทธิ์ที่หมดอายุไปแล้ว
+	ttl := time.Hour * 1
+	if nextChange != nil {
+		if untilChange := time.Until(*nextChange); untilChange < ttl {
+			ttl = untilChange
+		}
+	}
+	if len(roleNames) > 0 && ttl > 0 {
 		go func() {
 			encoded, _ := json.Marshal(roleNames)
-			if err := s.redis.Set(context.Background(), cacheKey, encoded, time.Hour*1).Err(); err != nil {
+			if err := s.redis.Set(context.Background(), cacheKey, encoded, ttl).Err(); err != nil {
 				log.Printf("⚠️ Failed to set cache: %v", err)
 			}
 		}()
 	}
 
-	return roleNames, nil
+	return roleNames, false, nil
+}
+
+// userRolesCacheKey แยก Cache ตาม Tenant (tenantID ว่าง = Role ระดับ Platform)
+func userRolesCacheKey(tenantID string, userID string) string {
+	if tenantID == "" {
+		return fmt.Sprintf("rbac:user:%s:roles", userID)
+	}
+	return fmt.Sprintf("rbac:tenant:%s:user:%s:roles", tenantID, userID)
 }
 
 // 1. สร้าง Role ใหม่
-func (s *rbacService) CreateRole(req *port.CreateRoleReq) error {
-	role := domain.Role{Name: req.Name}
-	if err := s.roleRepo.Create(context.Background(), &role); err != nil {
+func (s *rbacService) CreateRole(ctx context.Context, req *port.CreateRoleReq) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidRoleName)
+	}
+	role := domain.Role{Name: req.Name, Description: req.Description}
+	err := auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.roleRepo.Create(ctx, &role); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditRoleCreate, TargetType: port.AuditTargetRole, TargetID: role.Uid.String(), After: roleAuditView(&role)}, nil
+	})
+	if err != nil {
 		return err
 	}
-	return s.LoadPolicy()
+	return s.policyChanged(ctx)
 }
 
 // 2. สร้าง Permission ใหม่
-func (s *rbacService) CreatePermission(req *port.CreatePermReq) error {
-	perm := domain.Permission{Name: req.Name}
-	return s.permissionRepo.Create(context.Background(), &perm)
+func (s *rbacService) CreatePermission(ctx context.Context, req *port.CreatePermReq) error {
+	if err := domain.ValidatePermissionName(req.Name); err != nil {
+		return err
+	}
+	perm := domain.Permission{Name: req.Name, Description: req.Description}
+	return auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.permissionRepo.Create(ctx, &perm); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditPermissionCreate, TargetType: port.AuditTargetPermission, TargetID: perm.Uid.String(), After: permissionAuditView(&perm)}, nil
+	})
 }
 
 // 3. จับคู่ Role <-> Permission
-func (s *rbacService) AssignPermissionToRole(req *port.AssignPermReq) error {
+func (s *rbacService) AssignPermissionToRole(ctx context.Context, req *port.AssignPermReq) error {
 	// หา Role และ Permission จาก DB
-	role, err := s.roleRepo.GetRoleByName(context.Background(), req.RoleName)
+	role, err := s.roleRepo.GetRoleByName(ctx, req.RoleName)
 	if err != nil {
 		return err
 	}
 
-	perm, err := s.permissionRepo.GetPermissionByName(context.Background(), req.PermName)
+	perm, err := s.permissionRepo.GetPermissionByName(ctx, req.PermName)
 	if err != nil {
 		return err
 	}
 
+	// ตรวจเงื่อนไขก่อนบันทึก (LoadPolicy จะ Compile อีกรอบ)
+	if req.Condition != "" {
+		if _, err := compileCondition(req.Condition); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrInvalidCondition, err)
+		}
+	}
+
 	// เพิ่มความสัมพันธ์ (GORM Many2Many)
-	if err := s.roleRepo.AddAccosiatePermission(context.Background(), role.Uid.String(), perm.Uid.String()); err != nil {
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.roleRepo.AddAccosiatePermission(ctx, role.Uid.String(), perm.Uid.String(), req.Condition); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditRolePermissionAssign, TargetType: port.AuditTargetRole, TargetID: role.Uid.String(), After: req}, nil
+	})
+	if err != nil {
 		return err
 	}
 
 	// *** สำคัญ: Policy เปลี่ยน ต้องโหลดเข้า Memory ใหม่ ***
-	return s.LoadPolicy()
+	return s.policyChanged(ctx)
 }
 
 // 4. จับคู่ User <-> Role
-func (s *rbacService) AssignRoleToUser(req *port.AssignRoleReq) error {
+func (s *rbacService) AssignRoleToUser(ctx context.Context, req *port.AssignRoleReq) error {
 	// หา User และ Role
-	user, err := s.userRepo.GetUserByUID(context.Background(), req.UserID)
+	user, err := s.userRepo.GetUserByUID(ctx, req.UserID)
 	if err != nil {
 		return err
 	}
 
-	role, err := s.roleRepo.GetRoleByName(context.Background(), req.RoleName)
+	role, err := s.roleRepo.GetRoleByName(ctx, req.RoleName)
 	if err != nil {
 		return err
 	}
 
-	// เพิ่มความสัมพันธ์
-	if err := s.userRepo.AddAccosiateRole(context.Background(), user.Uid.String(), role.Uid.String()); err != nil {
+	if err := domain.ValidateWindow(req.ValidFrom, req.ValidUntil, time.Now()); err != nil {
+		return err
+	}
+
+	// เพิ่มความสัมพันธ์ (พร้อมช่วงเวลาใช้งาน ถ้ามี)
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.userRepo.AddAccosiateRole(ctx, user.Uid.String(), role.Uid.String(), req.ValidFrom, req.ValidUntil); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditUserRoleAssign, TargetType: port.AuditTargetUser, TargetID: user.Uid.String(), After: req}, nil
+	})
+	if err != nil {
 		return err
 	}
 
-	cacheKey := fmt.Sprintf("rbac:user:%s:roles", req.UserID)
-	s.redis.Del(context.Background(), cacheKey)
+	s.redis.Del(ctx, userRolesCacheKey("", req.UserID))
 
 	return nil
 }
 
+// SweepExpiredAssignments ลบ Assignment (ทั้ง Platform และ Tenant) ที่หมดอายุแล้ว
+// CheckAccess ไม่นับ Assignment ที่หมดอายุอยู่แล้ว ตัวนี้แค่เก็บกวาดตารางและ Cache
+// ลบกับ Audit อยู่ใน Transaction เดียวกัน: เขียน Audit ไม่ได้ก็ Rollback ไว้ให้รอบถัดไปลบใหม่
+func (s *rbacService) SweepExpiredAssignments(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	var removed []domain.UserRole
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if removed, err = s.userRepo.DeleteExpiredRoles(ctx, now); err != nil {
+			return err
+		}
+		for _, ur := range removed {
+			if err := s.audit.Record(ctx, port.AuditEntry{Action: domain.AuditUserRoleExpire, TargetType: port.AuditTargetUser, TargetID: ur.UserUid.String(), Before: ur}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, ur := range removed {
+		log.Printf("🧹 Removed expired role assignment: user=%s role=%s valid_until=%s", ur.UserUid, ur.RoleUid, ur.ValidUntil.Format(time.RFC3339))
+		s.redis.Del(ctx, userRolesCacheKey("", ur.UserUid.String()))
+	}
+
+	var removedTenant []domain.TenantRoleAssignment
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if removedTenant, err = s.tenantRepo.DeleteExpiredAssignments(ctx, now); err != nil {
+			return err
+		}
+		for _, a := range removedTenant {
+			if err := s.audit.Record(ctx, port.AuditEntry{Action: domain.AuditUserRoleExpire, TargetType: port.AuditTargetUser, TargetID: a.UserUid.String(), Before: a}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return len(removed), err
+	}
+	for _, a := range removedTenant {
+		log.Printf("🧹 Removed expired tenant role assignment: tenant=%s user=%s role=%s valid_until=%s", a.TenantUid, a.UserUid, a.RoleUid, a.ValidUntil.Format(time.RFC3339))
+		s.redis.Del(ctx, userRolesCacheKey(a.TenantUid.String(), a.UserUid.String()))
+	}
+
+	return len(removed) + len(removedTenant), nil
+}
+
 // 1. ยกเลิก Permission ออกจาก Role
-func (s *rbacService) RemovePermissionFromRole(req *port.UnassignPermReq) error {
-	role, err := s.roleRepo.GetRoleByName(context.Background(), req.RoleName)
+func (s *rbacService) RemovePermissionFromRole(ctx context.Context, req *port.UnassignPermReq) error {
+	role, err := s.roleRepo.GetRoleByName(ctx, req.RoleName)
 	if err != nil {
 		return err
 	}
 
-	perm, err := s.permissionRepo.GetPermissionByName(context.Background(), req.PermName)
+	perm, err := s.permissionRepo.GetPermissionByName(ctx, req.PermName)
 	if err != nil {
 		return err
 	}
 
-	if err := s.roleRepo.RemoveAssociatePermission(context.Background(), role.Uid.String(), perm.Uid.String()); err != nil {
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.roleRepo.RemoveAssociatePermission(ctx, role.Uid.String(), perm.Uid.String()); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditRolePermissionRemove, TargetType: port.AuditTargetRole, TargetID: role.Uid.String(), Before: req}, nil
+	})
+	if err != nil {
 		return err
 	}
 
 	// *** Policy เปลี่ยน ต้อง Reload Gorbac (Memory Cache) ใหม่ ***
-	return s.LoadPolicy()
+	return s.policyChanged(ctx)
 }
 
 // 2. ปลด Role ออกจาก User
-func (s *rbacService) RemoveRoleFromUser(req *port.UnassignRoleReq) error {
-	user, err := s.userRepo.GetUserByUID(context.Background(), req.UserID)
+func (s *rbacService) RemoveRoleFromUser(ctx context.Context, req *port.UnassignRoleReq) error {
+	user, err := s.userRepo.GetUserByUID(ctx, req.UserID)
 	if err != nil {
 		return err
 	}
 
-	role, err := s.roleRepo.GetRoleByName(context.Background(), req.RoleName)
+	role, err := s.roleRepo.GetRoleByName(ctx, req.RoleName)
 	if err != nil {
 		return err
 	}
 
-	if err := s.userRepo.RemoveAssociateRole(context.Background(), user.Uid.String(), role.Uid.String()); err != nil {
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.userRepo.RemoveAssociateRole(ctx, user.Uid.String(), role.Uid.String()); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditUserRoleRemove, TargetType: port.AuditTargetUser, TargetID: user.Uid.String(), Before: req}, nil
+	})
+	if err != nil {
 		return err
 	}
 
 	// *** สิทธิ์ของ User คนนี้เปลี่ยน ต้องลบ Cache ทิ้ง (Redis Cache) ***
-	cacheKey := fmt.Sprintf("rbac:user:%s:roles", req.UserID)
-	s.redis.Del(context.Background(), cacheKey)
+	s.redis.Del(ctx, userRolesCacheKey("", req.UserID))
+
+	return nil
+}
+
+// 3. ผูก Parent ให้ Role (Role Hierarchy)
+func (s *rbacService) AssignParentToRole(ctx context.Context, req *port.AssignParentReq) error {
+	role, err := s.roleRepo.GetRoleByName(ctx, req.RoleName)
+	if err != nil {
+		return err
+	}
+
+	parent, err := s.roleRepo.GetRoleByName(ctx, req.ParentName)
+	if err != nil {
+		return err
+	}
+
+	// กัน Hierarchy วนลูป (เช่น A -> B -> A) ก่อนบันทึกลง DB
+	if err := s.checkRoleCycle(role.Name, parent.Name); err != nil {
+		return err
+	}
+
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.roleRepo.AddParent(ctx, role.Uid.String(), parent.Uid.String()); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditRoleParentAssign, TargetType: port.AuditTargetRole, TargetID: role.Uid.String(), After: req}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// *** Hierarchy เปลี่ยน ต้อง Reload Gorbac ใหม่ ***
+	return s.policyChanged(ctx)
+}
+
+// 4. ปลด Parent ออกจาก Role
+func (s *rbacService) RemoveParentFromRole(ctx context.Context, req *port.UnassignParentReq) error {
+	role, err := s.roleRepo.GetRoleByName(ctx, req.RoleName)
+	if err != nil {
+		return err
+	}
+
+	parent, err := s.roleRepo.GetRoleByName(ctx, req.ParentName)
+	if err != nil {
+		return err
+	}
+
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.roleRepo.RemoveParent(ctx, role.Uid.String(), parent.Uid.String()); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditRoleParentRemove, TargetType: port.AuditTargetRole, TargetID: role.Uid.String(), Before: req}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.policyChanged(ctx)
+}
+
+// --- Helper: เช็คว่าการเพิ่ม edge role -> parent จะทำให้เกิด Cycle หรือไม่ ---
+// ถ้า role เป็นบรรพบุรุษของ parent อยู่แล้ว (หรือเป็นตัวเดียวกัน) แปลว่าจะวนลูป
+func (s *rbacService) checkRoleCycle(roleName string, parentName string) error {
+	if roleName == parentName {
+		return domain.ErrRoleCycle
+	}
+
+	roles, err := s.roleRepo.GetAll(context.Background())
+	if err != nil {
+		return err
+	}
+
+	parentsOf := make(map[string][]string, len(roles))
+	for _, r := range roles {
+		for _, p := range r.Parents {
+			parentsOf[r.Name] = append(parentsOf[r.Name], p.Name)
+		}
+	}
+
+	// DFS ไล่ขึ้นไปจาก parent ถ้าเจอ role แปลว่าวน
+	visited := make(map[string]bool)
+	stack := []string{parentName}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == roleName {
+			return fmt.Errorf("%w: %s -> %s", domain.ErrRoleCycle, roleName, parentName)
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		stack = append(stack, parentsOf[current]...)
+	}
+
+	return nil
+}
+
+// 5. ผูก Role ให้ User เฉพาะบน Resource
+func (s *rbacService) BindRoleOnResource(ctx context.Context, req *port.BindResourceRoleReq) error {
+	if req.ResourceType == "" || req.ResourceID == "" {
+		return fmt.Errorf("%w: resource_type and resource_id are required", domain.ErrValidation)
+	}
+
+	user, err := s.userRepo.GetUserByUID(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	role, err := s.roleRepo.GetRoleByName(ctx, req.RoleName)
+	if err != nil {
+		return err
+	}
+
+	binding := domain.ResourceRoleBinding{
+		UserUid:      user.Uid,
+		RoleUid:      role.Uid,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+	}
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.bindingRepo.Create(ctx, &binding); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditUserBindingAdd, TargetType: port.AuditTargetUser, TargetID: user.Uid.String(), After: req}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.redis.Del(ctx, userBindingsCacheKey(req.UserID))
+	return nil
+}
+
+// 6. ปลด Role ที่ผูกกับ Resource ออกจาก User
+func (s *rbacService) UnbindRoleOnResource(ctx context.Context, req *port.UnbindResourceRoleReq) error {
+	role, err := s.roleRepo.GetRoleByName(ctx, req.RoleName)
+	if err != nil {
+		return err
+	}
+
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.bindingRepo.Delete(ctx, req.UserID, role.Uid.String(), req.ResourceType, req.ResourceID); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditUserBindingRemove, TargetType: port.AuditTargetUser, TargetID: req.UserID, Before: req}, nil
+	})
+	if err != nil {
+		return err
+	}
 
+	s.redis.Del(ctx, userBindingsCacheKey(req.UserID))
 	return nil
 }
 
+func (s *rbacService) GetUserBindings(userID string) ([]domain.ResourceRoleBinding, error) {
+	return s.bindingRepo.GetByUserUID(context.Background(), userID)
+}
+
+// --- Tenant ---
+
+func (s *rbacService) CreateTenant(ctx context.Context, req *port.CreateTenantReq) error {
+	tenant := domain.Tenant{Name: req.Name}
+	return auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.tenantRepo.Create(ctx, &tenant); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditTenantCreate, TargetType: port.AuditTargetTenant, TargetID: tenant.Uid.String(), After: req}, nil
+	})
+}
+
+func (s *rbacService) GetAllTenants() ([]domain.Tenant, error) {
+	return s.tenantRepo.GetAll(context.Background())
+}
+
+// ให้ Role กับ User เฉพาะใน Tenant
+func (s *rbacService) AssignTenantRole(ctx context.Context, req *port.AssignTenantRoleReq) error {
+	tenant, err := s.tenantRepo.GetTenantByUID(ctx, req.TenantID)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetUserByUID(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	role, err := s.roleRepo.GetRoleByName(ctx, req.RoleName)
+	if err != nil {
+		return err
+	}
+
+	if err := domain.ValidateWindow(req.ValidFrom, req.ValidUntil, time.Now()); err != nil {
+		return err
+	}
+
+	assignment := domain.TenantRoleAssignment{
+		TenantUid:  tenant.Uid,
+		UserUid:    user.Uid,
+		RoleUid:    role.Uid,
+		ValidFrom:  req.ValidFrom,
+		ValidUntil: req.ValidUntil,
+	}
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.tenantRepo.AddRoleAssignment(ctx, &assignment); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditTenantRoleAssign, TargetType: port.AuditTargetUser, TargetID: user.Uid.String(), After: req}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.redis.Del(ctx, userRolesCacheKey(req.TenantID, req.UserID))
+	return nil
+}
+
+func (s *rbacService) RemoveTenantRole(ctx context.Context, req *port.UnassignTenantRoleReq) error {
+	role, err := s.roleRepo.GetRoleByName(ctx, req.RoleName)
+	if err != nil {
+		return err
+	}
+
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.tenantRepo.RemoveRoleAssignment(ctx, req.TenantID, req.UserID, role.Uid.String()); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditTenantRoleRemove, TargetType: port.AuditTargetUser, TargetID: req.UserID, Before: req}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.redis.Del(ctx, userRolesCacheKey(req.TenantID, req.UserID))
+	return nil
+}
+
+func (s *rbacService) GetUserTenantRoles(userID string) ([]domain.TenantRoleAssignment, error) {
+	return s.tenantRepo.GetAssignmentsByUserUID(context.Background(), userID)
+}
+
+// IsTenantMember: User เป็นสมาชิก Tenant ถ้ามี Role อย่างน้อย 1 ตัวใน Tenant นั้น
+func (s *rbacService) IsTenantMember(ctx context.Context, tenantID string, userID string) (bool, error) {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return false, nil
+	}
+	return s.tenantRepo.IsMember(ctx, tenantID, userID)
+}
+
+// --- Deny Rules ---
+
+func (s *rbacService) AddRoleDeny(ctx context.Context, req *port.RoleDenyReq) error {
+	if err := domain.ValidatePermissionName(req.PermName); err != nil {
+		return err
+	}
+
+	role, err := s.roleRepo.GetRoleByName(ctx, req.RoleName)
+	if err != nil {
+		return err
+	}
+
+	rule := domain.RoleDenyRule{RoleUid: role.Uid, Permission: req.PermName}
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.denyRepo.AddRoleDeny(ctx, &rule); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditRoleDenyAdd, TargetType: port.AuditTargetRole, TargetID: role.Uid.String(), After: req}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.policyChanged(ctx)
+}
+
+func (s *rbacService) RemoveRoleDeny(ctx context.Context, req *port.RoleDenyReq) error {
+	role, err := s.roleRepo.GetRoleByName(ctx, req.RoleName)
+	if err != nil {
+		return err
+	}
+
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.denyRepo.RemoveRoleDeny(ctx, role.Uid.String(), req.PermName); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditRoleDenyRemove, TargetType: port.AuditTargetRole, TargetID: role.Uid.String(), Before: req}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.policyChanged(ctx)
+}
+
+func (s *rbacService) AddUserDeny(ctx context.Context, req *port.UserDenyReq) error {
+	if err := domain.ValidatePermissionName(req.PermName); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetUserByUID(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	rule := domain.UserDenyRule{UserUid: user.Uid, Permission: req.PermName}
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.denyRepo.AddUserDeny(ctx, &rule); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditUserDenyAdd, TargetType: port.AuditTargetUser, TargetID: user.Uid.String(), After: req}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.policyChanged(ctx)
+}
+
+func (s *rbacService) RemoveUserDeny(ctx context.Context, req *port.UserDenyReq) error {
+	err := auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.denyRepo.RemoveUserDeny(ctx, req.UserID, req.PermName); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditUserDenyRemove, TargetType: port.AuditTargetUser, TargetID: req.UserID, Before: req}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.policyChanged(ctx)
+}
+
+func (s *rbacService) GetUserDenies(userID string) ([]domain.UserDenyRule, error) {
+	return s.denyRepo.GetUserDenies(context.Background(), userID)
+}
+
 func (s *rbacService) GetAllRoles() ([]domain.Role, error) {
 	return s.roleRepo.GetAll(context.Background())
 }
@@ -240,6 +1072,29 @@ func (s *rbacService) GetAllPermissions() ([]domain.Permission, error) {
 	return s.permissionRepo.GetAll(context.Background())
 }
 
+// GetAllPermissionsExpanded เหมือน GetAllPermissions แต่บอกด้วยว่า Wildcard แต่ละตัวครอบคลุม Permission ไหนบ้าง
+func (s *rbacService) GetAllPermissionsExpanded() ([]port.ExpandedPermission, error) {
+	perms, err := s.permissionRepo.GetAll(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]port.ExpandedPermission, 0, len(perms))
+	for _, p := range perms {
+		expanded := port.ExpandedPermission{Permission: p}
+		if domain.IsWildcardPermission(p.Name) {
+			expanded.Covers = []string{}
+			for _, other := range perms {
+				if !domain.IsWildcardPermission(other.Name) && domain.PermissionMatches(p.Name, other.Name) {
+					expanded.Covers = append(expanded.Covers, other.Name)
+				}
+			}
+		}
+		result = append(result, expanded)
+	}
+	return result, nil
+}
+
 func (s *rbacService) GetUserRoles(userID string) ([]domain.Role, error) {
 	return s.roleRepo.GetRoleByUserUID(context.Background(), userID)
 }
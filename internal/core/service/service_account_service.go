@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+// --- API Key: "rbk_<id 8 ตัว>_<secret>" ---
+// ส่วน "rbk_<id>" เป็น Prefix ที่เก็บใน DB ไว้ค้นหา (และแสดงให้ Admin ดูว่าเป็น Key ไหน)
+// ทั้ง Key ถูก Hash ด้วย SHA-256 (Secret สุ่ม 256 bits ไม่ต้องใช้ bcrypt เหมือน Refresh Token)
+const (
+	apiKeyScheme   = "rbk_"
+	apiKeyIDLength = 8
+	// apiKeyTouchInterval อัปเดต last_used_at ไม่บ่อยกว่านี้ (ไม่ต้องเขียน DB ทุก Request)
+	apiKeyTouchInterval = time.Minute
+	// serviceAccountEmailDomain ใช้ TLD .invalid (RFC 2606) เพราะ users.email ห้ามว่างและห้ามซ้ำ
+	serviceAccountEmailDomain = "@service-accounts.invalid"
+)
+
+type serviceAccountService struct {
+	userRepo   port.UserRepository
+	apiKeyRepo port.APIKeyRepository
+	denylist   port.TokenDenylist
+	tx         port.Transactor
+	audit      port.AuditLogger
+}
+
+func NewServiceAccountService(userRepo port.UserRepository, apiKeyRepo port.APIKeyRepository, denylist port.TokenDenylist, tx port.Transactor, audit port.AuditLogger) port.ServiceAccountService {
+	return &serviceAccountService{userRepo: userRepo, apiKeyRepo: apiKeyRepo, denylist: denylist, tx: tx, audit: audit}
+}
+
+func (s *serviceAccountService) CreateServiceAccount(ctx context.Context, req *port.CreateServiceAccountReq) (*domain.User, error) {
+	name := strings.TrimSpace(req.Name)
+	if msg := domain.ValidateUsername(name); msg != "" {
+		verr := &domain.ValidationError{}
+		verr.Add("name", msg)
+		return nil, verr
+	}
+
+	account := &domain.User{
+		Username:    name,
+		Email:       strings.ToLower(name) + serviceAccountEmailDomain,
+		Kind:        domain.UserKindService,
+		Description: strings.TrimSpace(req.Description),
+	}
+	err := auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.userRepo.Create(ctx, account); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditServiceAccountCreate, TargetType: port.AuditTargetServiceAccount, TargetID: account.Uid.String(), After: userAuditView(account)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *serviceAccountService) ListServiceAccounts(ctx context.Context) ([]domain.User, error) {
+	accounts, _, err := s.userRepo.List(ctx, port.UserFilter{Kind: domain.UserKindService, Limit: -1})
+	return accounts, err
+}
+
+func (s *serviceAccountService) DeleteServiceAccount(ctx context.Context, accountID string) error {
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.apiKeyRepo.RevokeAllForUser(ctx, accountID); err != nil {
+			return port.AuditEntry{}, err
+		}
+		if err := s.userRepo.Delete(ctx, accountID); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditServiceAccountDelete, TargetType: port.AuditTargetServiceAccount, TargetID: accountID, Before: userAuditView(account)}, nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.denylist.SetUserDisabled(ctx, accountID, true)
+}
+
+func (s *serviceAccountService) CreateAPIKey(ctx context.Context, accountID string, req *port.CreateAPIKeyReq) (*domain.APIKey, string, error) {
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	verr := &domain.ValidationError{}
+	if name == "" {
+		verr.Add("name", "is required")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		verr.Add("expires_at", "must be in the future")
+	}
+	if err := verr.OrNil(); err != nil {
+		return nil, "", err
+	}
+
+	var key *domain.APIKey
+	var raw string
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		var err error
+		if key, raw, err = s.issueKey(ctx, account, name, req.ExpiresAt); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditAPIKeyCreate, TargetType: port.AuditTargetAPIKey, TargetID: key.Uid.String(), After: apiKeyAuditView(key)}, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return key, raw, nil
+}
+
+func (s *serviceAccountService) ListAPIKeys(ctx context.Context, accountID string) ([]domain.APIKey, error) {
+	if _, err := s.getAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	return s.apiKeyRepo.ListByUser(ctx, accountID)
+}
+
+func (s *serviceAccountService) RotateAPIKey(ctx context.Context, accountID string, keyID string, grace time.Duration) (*domain.APIKey, string, error) {
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return nil, "", err
+	}
+	old, err := s.getKey(ctx, accountID, keyID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !old.Active(time.Now()) {
+		return nil, "", domain.ErrNotFound
+	}
+
+	var key *domain.APIKey
+	var raw string
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		var err error
+		if key, raw, err = s.issueKey(ctx, account, old.Name, old.ExpiresAt); err != nil {
+			return port.AuditEntry{}, err
+		}
+
+		// Key เก่าใช้ต่อได้อีก grace ให้ฝั่ง Client เปลี่ยน Key ได้โดยไม่สะดุด (แต่ไม่เกินวันหมดอายุเดิม)
+		if grace > 0 {
+			expiresAt := time.Now().Add(grace)
+			if old.ExpiresAt != nil && old.ExpiresAt.Before(expiresAt) {
+				expiresAt = *old.ExpiresAt
+			}
+			err = s.apiKeyRepo.SetExpiry(ctx, old.Uid.String(), expiresAt)
+		} else {
+			err = s.apiKeyRepo.Revoke(ctx, old.Uid.String())
+		}
+		if err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{
+			Action:     domain.AuditAPIKeyRotate,
+			TargetType: port.AuditTargetAPIKey,
+			TargetID:   old.Uid.String(),
+			Before:     apiKeyAuditView(old),
+			After:      map[string]any{"replaced_by": apiKeyAuditView(key), "grace": grace.String()},
+		}, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return key, raw, nil
+}
+
+func (s *serviceAccountService) RevokeAPIKey(ctx context.Context, accountID string, keyID string) error {
+	key, err := s.getKey(ctx, accountID, keyID)
+	if err != nil {
+		return err
+	}
+	return auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.apiKeyRepo.Revoke(ctx, keyID); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditAPIKeyRevoke, TargetType: port.AuditTargetAPIKey, TargetID: keyID, Before: apiKeyAuditView(key)}, nil
+	})
+}
+
+// AuthenticateAPIKey หา Key จาก Prefix แล้วเทียบ Hash แบบ Constant Time และบัญชีเจ้าของต้องยังใช้งานได้
+func (s *serviceAccountService) AuthenticateAPIKey(ctx context.Context, rawKey string) (string, error) {
+	prefix, ok := apiKeyPrefix(rawKey)
+	if !ok {
+		return "", domain.ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.GetByPrefix(ctx, prefix)
+	if errors.Is(err, domain.ErrNotFound) {
+		return "", domain.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(hashToken(rawKey)), []byte(key.KeyHash)) != 1 || !key.Active(now) {
+		return "", domain.ErrInvalidAPIKey
+	}
+
+	// บัญชีที่ถูกปิด / ลบไปแล้ว Key ต้องใช้ไม่ได้ทันที (GetUserByUID ไม่คืนบัญชีที่ Soft Delete)
+	account, err := s.userRepo.GetUserByUID(ctx, key.UserUid.String())
+	if errors.Is(err, domain.ErrNotFound) {
+		return "", domain.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return "", err
+	}
+	if account.DisabledAt != nil || account.DeletedAt.Valid {
+		return "", domain.ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.apiKeyRepo.TouchLastUsed(ctx, key.Uid.String(), now); err != nil {
+			log.Printf("⚠️ Failed to update API key last used (%s): %v", key.Prefix, err)
+		}
+	}
+	return key.UserUid.String(), nil
+}
+
+// --- Helpers ---
+
+func (s *serviceAccountService) getAccount(ctx context.Context, accountID string) (*domain.User, error) {
+	account, err := s.userRepo.GetUserByUID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.IsServiceAccount() {
+		return nil, domain.ErrNotServiceAccount
+	}
+	return account, nil
+}
+
+// getKey Key ต้องเป็นของ Service Account นี้ (ไม่งั้นถือว่าไม่พบ)
+func (s *serviceAccountService) getKey(ctx context.Context, accountID string, keyID string) (*domain.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByUID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.UserUid.String() != accountID {
+		return nil, domain.ErrNotFound
+	}
+	return key, nil
+}
+
+func (s *serviceAccountService) issueKey(ctx context.Context, account *domain.User, name string, expiresAt *time.Time) (*domain.APIKey, string, error) {
+	raw, prefix, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &domain.APIKey{
+		UserUid:   account.Uid,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashToken(raw),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+	return key, raw, nil
+}
+
+// generateAPIKey คืน (Key ตัวจริง, Prefix)
+func generateAPIKey() (string, string, error) {
+	id := make([]byte, 5) // 5 bytes = Base32 8 ตัวพอดี
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefix := apiKeyScheme + strings.ToLower(totpEncoding.EncodeToString(id))
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(secret), prefix, nil
+}
+
+// apiKeyPrefix ตัด "rbk_<id>" ออกจาก Key ที่ส่งมา
+func apiKeyPrefix(rawKey string) (string, bool) {
+	n := len(apiKeyScheme) + apiKeyIDLength
+	if len(rawKey) <= n+1 || !strings.HasPrefix(rawKey, apiKeyScheme) || rawKey[n] != '_' {
+		return "", false
+	}
+	return rawKey[:n], true
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+// StartAssignmentSweeper ลบ Role Assignment ที่หมดอายุทุกๆ interval จนกว่า ctx จะถูกยกเลิก
+func StartAssignmentSweeper(ctx context.Context, rbacSvc port.RBACService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n, err := rbacSvc.SweepExpiredAssignments(ctx)
+				if err != nil {
+					log.Printf("⚠️ Failed to sweep expired role assignments: %v", err)
+					continue
+				}
+				if n > 0 {
+					log.Printf("🧹 Swept %d expired role assignments", n)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/config"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// accessTokenClaims ใช้ทั้งตอนเซ็น (authService) และตอนตรวจ (tokenVerifier)
+// ใช้ Struct แทน MapClaims เพื่อไม่ต้อง Type Assert เอง (กัน Panic จาก Claim ผิดชนิด)
+type accessTokenClaims struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	TenantID string `json:"tid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type tokenVerifier struct {
+	keySet  port.KeySet
+	allowed map[string]bool
+	parser  *jwt.Parser
+}
+
+func NewTokenVerifier(keySet port.KeySet, cfg config.AuthConfig) (port.TokenVerifier, error) {
+	allowed := make(map[string]bool, len(cfg.AllowedAlgorithms))
+	for _, alg := range cfg.AllowedAlgorithms {
+		// อนุญาตเฉพาะ Asymmetric เท่านั้น กัน Algorithm Confusion (เช่นเอา Public Key ไปใช้เป็น HMAC Secret)
+		if alg != "RS256" && alg != "EdDSA" {
+			return nil, fmt.Errorf("algorithm %q is not allowed for token verification", alg)
+		}
+		allowed[alg] = true
+	}
+	if len(allowed) == 0 {
+		return nil, errors.New("auth.allowed_algorithms must not be empty")
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(30*time.Second), // เผื่อนาฬิกาแต่ละเครื่องไม่ตรงกัน
+	)
+
+	return &tokenVerifier{keySet: keySet, allowed: allowed, parser: parser}, nil
+}
+
+func (v *tokenVerifier) Verify(tokenString string) (*port.AccessClaims, error) {
+	if tokenString == "" {
+		return nil, domain.ErrTokenMissing
+	}
+
+	var claims accessTokenClaims
+	_, err := v.parser.ParseWithClaims(tokenString, &claims, v.keyFunc)
+	if err != nil {
+		return nil, mapJWTError(err)
+	}
+
+	// Claim ที่ Library ไม่ได้บังคับให้มี แต่เราต้องใช้
+	if claims.IssuedAt == nil || claims.ID == "" {
+		return nil, domain.ErrTokenClaimsInvalid
+	}
+	if _, err := uuid.Parse(claims.UserID); err != nil {
+		return nil, domain.ErrTokenClaimsInvalid
+	}
+	if claims.TenantID != "" {
+		if _, err := uuid.Parse(claims.TenantID); err != nil {
+			return nil, domain.ErrTokenClaimsInvalid
+		}
+	}
+
+	return &port.AccessClaims{
+		TokenID:   claims.ID,
+		UserID:    claims.UserID,
+		Username:  claims.Username,
+		Email:     claims.Email,
+		TenantID:  claims.TenantID,
+		IssuedAt:  claims.IssuedAt.Time,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// keyFunc เช็ค Algorithm กับ Allow-list และเลือก Public Key ตาม kid
+// Algorithm ใน Header ต้องตรงกับชนิดของกุญแจนั้นด้วย
+func (v *tokenVerifier) keyFunc(t *jwt.Token) (interface{}, error) {
+	alg := t.Method.Alg()
+	if !v.allowed[alg] {
+		return nil, domain.ErrTokenAlgorithm
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	keyAlg, key, ok := v.keySet.VerificationKey(kid)
+	if !ok {
+		return nil, domain.ErrTokenUnknownKey
+	}
+	if keyAlg != alg {
+		return nil, domain.ErrTokenAlgorithm
+	}
+	return key, nil
+}
+
+// mapJWTError แปลง Error ของ jwt Library เป็น domain.ErrToken*
+func mapJWTError(err error) error {
+	switch {
+	// Error จาก keyFunc ถูก Wrap ไว้ใน jwt.ErrTokenUnverifiable
+	case errors.Is(err, domain.ErrTokenAlgorithm):
+		return domain.ErrTokenAlgorithm
+	case errors.Is(err, domain.ErrTokenUnknownKey):
+		return domain.ErrTokenUnknownKey
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return domain.ErrTokenMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return domain.ErrTokenSignatureInvalid
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return domain.ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return domain.ErrTokenNotYetValid
+	default:
+		// iss / aud ไม่ตรง, Claim ที่บังคับหายไป, Claim ผิดชนิด ฯลฯ
+		return domain.ErrTokenClaimsInvalid
+	}
+}
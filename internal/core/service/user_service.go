@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/domain"
+	"github.com/chonlasit2000/rbac-hexagonal-gorbac/internal/core/port"
+)
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 200
+)
+
+type userService struct {
+	userRepo   port.UserRepository
+	apiKeyRepo port.APIKeyRepository
+	authSvc    port.AuthService
+	denylist   port.TokenDenylist
+	tx         port.Transactor
+	audit      port.AuditLogger
+}
+
+func NewUserService(userRepo port.UserRepository, apiKeyRepo port.APIKeyRepository, authSvc port.AuthService, denylist port.TokenDenylist, tx port.Transactor, audit port.AuditLogger) port.UserService {
+	return &userService{userRepo: userRepo, apiKeyRepo: apiKeyRepo, authSvc: authSvc, denylist: denylist, tx: tx, audit: audit}
+}
+
+func (s *userService) ListUsers(ctx context.Context, query *port.UserListQuery) ([]domain.User, int64, error) {
+	switch query.Status {
+	case "", port.UserStatusActive, port.UserStatusDisabled, port.UserStatusDeleted:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidQuery, query.Status)
+	}
+	switch query.Kind {
+	case "", domain.UserKindHuman, domain.UserKindService:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown kind %q", domain.ErrInvalidQuery, query.Kind)
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 {
+		query.PageSize = defaultUserPageSize
+	}
+	if query.PageSize > maxUserPageSize {
+		return nil, 0, fmt.Errorf("%w: page_size must be at most %d", domain.ErrInvalidQuery, maxUserPageSize)
+	}
+
+	return s.userRepo.List(ctx, port.UserFilter{
+		Search: strings.TrimSpace(query.Search),
+		Status: query.Status,
+		Kind:   query.Kind,
+		Limit:  query.PageSize,
+		Offset: (query.Page - 1) * query.PageSize,
+	})
+}
+
+func (s *userService) GetUser(ctx context.Context, userID string) (*domain.User, error) {
+	return s.userRepo.GetUserByUID(ctx, userID)
+}
+
+func (s *userService) UpdateUser(ctx context.Context, userID string, req *port.UpdateUserReq) (*domain.User, error) {
+	user, err := s.userRepo.GetUserByUID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	before := userAuditView(user)
+
+	verr := &domain.ValidationError{}
+	if req.Username != nil {
+		user.Username = strings.TrimSpace(*req.Username)
+		if msg := domain.ValidateUsername(user.Username); msg != "" {
+			verr.Add("username", msg)
+		}
+	}
+	if req.Email != nil {
+		email := strings.TrimSpace(*req.Email)
+		// อีเมลใหม่ยังไม่ได้พิสูจน์ว่าเป็นของ User
+		if !strings.EqualFold(email, user.Email) {
+			user.EmailVerifiedAt = nil
+		}
+		user.Email = email
+		if msg := domain.ValidateEmail(user.Email); msg != "" {
+			verr.Add("email", msg)
+		}
+	}
+	if err := verr.OrNil(); err != nil {
+		return nil, err
+	}
+
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditUserUpdate, TargetType: port.AuditTargetUser, TargetID: userID, Before: before, After: userAuditView(user)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DisableUser ตั้ง Marker ก่อนเพิกถอน Session เพื่อให้ Middleware ปฏิเสธ Token ที่ยังไม่หมดอายุได้ทันที
+func (s *userService) DisableUser(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetUserByUID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	// Disable ซ้ำไม่บันทึก Audit แต่ยังเพิกถอน Key / Session ซ้ำให้ (เผื่อครั้งก่อนทำไม่ครบ)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.revokeAPIKeys(ctx, user); err != nil {
+			return err
+		}
+		if user.DisabledAt != nil {
+			return nil
+		}
+		now := time.Now()
+		user.DisabledAt = &now
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, port.AuditEntry{Action: domain.AuditUserDisable, TargetType: port.AuditTargetUser, TargetID: userID})
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.denylist.SetUserDisabled(ctx, userID, true); err != nil {
+		return err
+	}
+	return s.authSvc.RevokeAllSessions(ctx, userID)
+}
+
+func (s *userService) EnableUser(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetUserByUID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.DisabledAt != nil {
+		user.DisabledAt = nil
+		err := auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+			if err := s.userRepo.Update(ctx, user); err != nil {
+				return port.AuditEntry{}, err
+			}
+			return port.AuditEntry{Action: domain.AuditUserEnable, TargetType: port.AuditTargetUser, TargetID: userID}, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return s.denylist.SetUserDisabled(ctx, userID, false)
+}
+
+// DeleteUser ใช้ Marker เดียวกับ Disable เพราะ Token ที่ออกไปแล้วยังไม่รู้ว่า User ถูกลบ
+func (s *userService) DeleteUser(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetUserByUID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.revokeAPIKeys(ctx, user); err != nil {
+			return port.AuditEntry{}, err
+		}
+		if err := s.userRepo.Delete(ctx, userID); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditUserDelete, TargetType: port.AuditTargetUser, TargetID: userID, Before: userAuditView(user)}, nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.denylist.SetUserDisabled(ctx, userID, true); err != nil {
+		return err
+	}
+	return s.authSvc.RevokeAllSessions(ctx, userID)
+}
+
+// RestoreUser กู้คืนพร้อมสถานะเดิม (ถ้าถูก Disable ไว้ก่อนลบ ก็ยัง Disable อยู่)
+func (s *userService) RestoreUser(ctx context.Context, userID string) error {
+	var user *domain.User
+	err := auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if err := s.userRepo.Restore(ctx, userID); err != nil {
+			return port.AuditEntry{}, err
+		}
+		var err error
+		if user, err = s.userRepo.GetUserByUID(ctx, userID); err != nil {
+			return port.AuditEntry{}, err
+		}
+		return port.AuditEntry{Action: domain.AuditUserRestore, TargetType: port.AuditTargetUser, TargetID: userID, After: userAuditView(user)}, nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.denylist.SetUserDisabled(ctx, userID, user.DisabledAt != nil)
+}
+
+func (s *userService) ForcePasswordReset(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetUserByUID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	err = auditedTx(ctx, s.tx, s.audit, func(ctx context.Context) (port.AuditEntry, error) {
+		if !user.MustChangePassword {
+			user.MustChangePassword = true
+			if err := s.userRepo.Update(ctx, user); err != nil {
+				return port.AuditEntry{}, err
+			}
+		}
+		return port.AuditEntry{Action: domain.AuditUserForcePasswordReset, TargetType: port.AuditTargetUser, TargetID: userID}, nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.authSvc.RevokeAllSessions(ctx, userID)
+}
+
+// revokeAPIKeys ปิด / ลบ Service Account แล้ว API Key ต้องใช้ไม่ได้อีก (เปิดบัญชีคืนต้องออก Key ใหม่)
+func (s *userService) revokeAPIKeys(ctx context.Context, user *domain.User) error {
+	if !user.IsServiceAccount() {
+		return nil
+	}
+	return s.apiKeyRepo.RevokeAllForUser(ctx, user.Uid.String())
+}